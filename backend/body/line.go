@@ -8,22 +8,16 @@ type LINEUser struct {
 	StatusMessage string
 }
 
-// LINEAPIでIDトークンを用いて取得できるユーザー情報
+// LINEのIDトークンのペイロード
 type VerifyResponseBody struct {
-	Iss     string
-	Sub     string
-	Aud     string
-	Exp     int
-	Iat     int
-	Nonce   string
-	Amr     []string
-	Name    string
-	Picture string
-	Email   string
-}
-
-type RevokeRequestBody struct {
-	ClientID      string
-	ClientSercret string
-	AccessToken   string
+	Iss     string   `json:"iss"`
+	Sub     string   `json:"sub"`
+	Aud     string   `json:"aud"`
+	Exp     int      `json:"exp"`
+	Iat     int      `json:"iat"`
+	Nonce   string   `json:"nonce"`
+	Amr     []string `json:"amr"`
+	Name    string   `json:"name"`
+	Picture string   `json:"picture"`
+	Email   string   `json:"email"`
 }
@@ -0,0 +1,15 @@
+package body
+
+// limitかcursorを指定した一覧の結果。次のページがなければNextCursorはnull
+type PageResponse struct {
+	Items      interface{} `json:"items"`
+	NextCursor *string     `json:"next_cursor"`
+}
+
+func NewPageResponse(items interface{}, nextCursor string) PageResponse {
+	res := PageResponse{Items: items}
+	if nextCursor != "" {
+		res.NextCursor = &nextCursor
+	}
+	return res
+}
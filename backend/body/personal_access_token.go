@@ -0,0 +1,15 @@
+package body
+
+import "github.com/yot-sailing/TSUNTSUN/domain"
+
+// 個人用アクセストークンの発行で受け取る値。scopesはread、write、admin
+type PersonalAccessTokenCreateRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// 発行したトークン。tokenはこのときしか返さない
+type PersonalAccessTokenCreateResponse struct {
+	domain.PersonalAccessToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,14 @@
+package body
+
+import "github.com/yot-sailing/TSUNTSUN/domain"
+
+// ログインの結果。ユーザー情報と発行したトークン
+type LoginResponse struct {
+	UesrExcludeLine
+	domain.SessionToken
+}
+
+// トークンの再発行とログアウトで受け取る値
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
@@ -0,0 +1,12 @@
+package body
+
+// タグ名の変更で受け取る値
+type TagRenameRequest struct {
+	Name string `json:"name"`
+}
+
+// タグの統合で受け取る値。SourceIDのタグをTargetIDのタグにまとめる
+type TagMergeRequest struct {
+	SourceID int `json:"sourceID"`
+	TargetID int `json:"targetID"`
+}
@@ -0,0 +1,41 @@
+package body
+
+// 積読の追加で受け取る値
+type TsundokuCreateRequest struct {
+	Category     string `json:"category"`
+	Title        string `json:"title"`
+	Author       string `json:"author"`
+	URL          string `json:"url"`
+	Note         string `json:"note"`
+	Deadline     string `json:"deadline"`     // YYYY-MM-DD
+	RequiredTime string `json:"requiredTime"` // "90"、"1h30m"、"1時間半"、"30分"など。単位がなければ分
+	Priority     int    `json:"priority"`     // 1から5。0なら3
+	TotalPages   int    `json:"totalPages"`   // 本のページ数。0なら不明
+	Language     string `json:"language"`     // jaかen。サイトは空ならページの文章から決める
+}
+
+// 積読の部分更新で受け取る値。送られてこなかった項目はnilのまま
+type TsundokuUpdateRequest struct {
+	Category     *string `json:"category"`
+	Title        *string `json:"title"`
+	Author       *string `json:"author"`
+	URL          *string `json:"url"`
+	Note         *string `json:"note"`
+	Deadline     *string `json:"deadline"`
+	RequiredTime *string `json:"requiredTime"`
+	Priority     *int    `json:"priority"`
+	TotalPages   *int    `json:"totalPages"`
+	Language     *string `json:"language"`
+}
+
+// 読書のタイマーを止めるときに受け取る値
+type StopReadingSessionRequest struct {
+	Page     *int `json:"page"`     // 本ならどのページまで読んだか
+	Finished bool `json:"finished"` // 読み終わったか
+}
+
+// 本をどこまで読んだかの記録で受け取る値
+type ProgressRequest struct {
+	Page    int `json:"page"`
+	Minutes int `json:"minutes"` // 読んでいた時間。0なら不明
+}
@@ -5,6 +5,32 @@ import "time"
 type UesrExcludeLine struct {
 	ID        int       `gorm:"primary_key" json:"id"`
 	Name      string    `gorm:"not null" json:"name"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+// 管理者がユーザーを作るときに受け取る値。IDプロバイダーの情報や退会の予約などは受け取らない
+type CreateUserRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"` // userかadmin。省略するとuser
+}
+
+// 管理者がユーザーの役割を変えるときに受け取る値
+type ChangeRoleRequest struct {
+	Role string `json:"role"` // userかadmin
+}
+
+// プロフィールの部分更新で受け取る値。送られてこなかった項目はnilのまま
+type UpdateMeRequest struct {
+	Name         *string `json:"name"`
+	Picture      *string `json:"picture"`
+	Timezone     *string `json:"timezone"`
+	ReadingSpeed *int    `json:"readingSpeed"` // 1分間に読む文字数。0で未設定に戻す
+}
+
+// 退会で受け取る値。IDプロバイダーのアクセストークンを送ると無効にする
+type DeleteAccountRequest struct {
+	Provider    string `json:"provider" form:"provider"`
+	AccessToken string `json:"accessToken" form:"access_token"`
+}
@@ -0,0 +1,32 @@
+// ネットワークにつながずにログインを試すための偽のIDプロバイダー
+// LINEログインと同じAPIとOpenID Connectのエンドポイントを持つ
+//
+//	go run ./cmd/fakeidp -addr :9000 -client-id 1234
+//	curl -X POST 'localhost:9000/token?sub=alice&name=Alice&nonce=abc'
+//
+// バックエンドはLINE_API_BASE_URL=http://localhost:9000とLINE_ID_TOKEN_ISSUER=http://localhost:9000、
+// またはIDENTITY_PROVIDER=oidcとOIDC_ISSUER=http://localhost:9000で使う
+// IDトークンは起動するたびに作るES256の鍵で署名する
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/yot-sailing/TSUNTSUN/fakeidp"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer URL")
+	clientID := flag.String("client-id", "", "client_id returned by the LINE verify endpoint (CHANNEL_ID)")
+	flag.Parse()
+
+	s, err := fakeidp.New(*issuer, *clientID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("fake identity provider listening on %s (issuer %s)", *addr, *issuer)
+	log.Fatal(http.ListenAndServe(*addr, s))
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// 退会する前に書き出すユーザーのすべてのデータ。ゴミ箱の中も含む
+type AccountExport struct {
+	ExportedAt           time.Time             `json:"exportedAt"`
+	User                 User                  `json:"user"`
+	Identities           []Identity            `json:"identities"`
+	Tsundokus            []Tsundoku            `json:"tsundokus"`
+	Tags                 []Tag                 `json:"tags"`
+	TsundokuTags         []TsundokuTag         `json:"tsundokuTags"`
+	ProgressLogs         []ProgressLog         `json:"progressLogs"`
+	ReadingSessions      []ReadingSession      `json:"readingSessions"`
+	PersonalAccessTokens []PersonalAccessToken `json:"personalAccessTokens"`
+}
@@ -0,0 +1,85 @@
+package domain
+
+import "errors"
+
+// エラーの種類。HTTPのステータスコードとエラーレスポンスのcodeに対応する
+const (
+	ErrorKindUnauthorized = "unauthorized"
+	ErrorKindNotFound     = "not_found"
+	ErrorKindForbidden    = "forbidden"
+	ErrorKindConflict     = "conflict"
+	ErrorKindValidation   = "validation"
+	ErrorKindInternal     = "internal"
+)
+
+// 各層で返すエラー。errors.Isでは種類が同じなら一致とみなす
+type Error struct {
+	Kind    string
+	Message string
+	Err     error // 元になったエラー
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind
+}
+
+var (
+	// 認証されていないか、トークンが無効・期限切れ
+	ErrUnauthorized = &Error{Kind: ErrorKindUnauthorized, Message: "unauthorized"}
+	// 対象のレコードが存在しない
+	ErrNotFound = &Error{Kind: ErrorKindNotFound, Message: "not found"}
+	// 他のユーザーが管理しているレコードを操作しようとした
+	ErrForbidden = &Error{Kind: ErrorKindForbidden, Message: "forbidden"}
+	// 一意であるべき値が重複した
+	ErrConflict = &Error{Kind: ErrorKindConflict, Message: "conflict"}
+	// 入力値が正しくない
+	ErrValidation = &Error{Kind: ErrorKindValidation, Message: "validation failed"}
+	// DBなど内部で失敗した
+	ErrInternal = &Error{Kind: ErrorKindInternal, Message: "internal error"}
+	// 読書状態をその状態には遷移させられない
+	ErrInvalidStatusTransition = &Error{Kind: ErrorKindConflict, Message: "invalid status transition"}
+)
+
+func UnauthorizedError(message string) error {
+	return &Error{Kind: ErrorKindUnauthorized, Message: message}
+}
+
+func NotFoundError(message string) error {
+	return &Error{Kind: ErrorKindNotFound, Message: message}
+}
+
+func ForbiddenError(message string) error {
+	return &Error{Kind: ErrorKindForbidden, Message: message}
+}
+
+func ConflictError(message string) error {
+	return &Error{Kind: ErrorKindConflict, Message: message}
+}
+
+func ValidationError(message string) error {
+	return &Error{Kind: ErrorKindValidation, Message: message}
+}
+
+// すでにdomain.Errorならそのまま返す
+func InternalError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return err
+	}
+	return &Error{Kind: ErrorKindInternal, Message: "internal error", Err: err}
+}
@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"time"
+)
+
+// ユーザーがログインに使うIDプロバイダーのアカウント。一人のユーザーが複数持てる
+type Identity struct {
+	ID        int       `gorm:"primary_key" json:"id"`
+	UserID    int       `gorm:"not null;index" json:"userID"`
+	Provider  string    `gorm:"not null;unique_index:idx_identities_provider_subject" json:"provider"`
+	Subject   string    `gorm:"not null;unique_index:idx_identities_provider_subject" json:"subject"` // プロバイダー内で一意なID
+	CreatedAt time.Time `json:"createdAt"`
+}
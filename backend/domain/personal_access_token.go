@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 個人用アクセストークンで許可する操作
+const (
+	ScopeRead  = "read"  // 自分のデータの参照
+	ScopeWrite = "write" // 自分のデータの追加・変更・削除。readも含む
+	ScopeAdmin = "admin" // トークンの管理やアカウントの削除。writeとreadも含む
+)
+
+// 個人用アクセストークンの先頭につける文字列。ログインで発行するトークンと区別する
+const PersonalAccessTokenPrefix = "tsun_"
+
+// スクリプトやChrome拡張から使う、名前をつけた個人用アクセストークン。トークンはハッシュだけを保存する
+type PersonalAccessToken struct {
+	ID         int        `gorm:"primary_key" json:"id"`
+	UserID     int        `gorm:"not null;index" json:"userID"`
+	Name       string     `gorm:"not null" json:"name"`
+	TokenHash  string     `gorm:"not null;unique_index" json:"-"`
+	Scopes     Scopes     `gorm:"type:text;not null" json:"scopes"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	LastUsedIP string     `json:"lastUsedIP"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// スペース区切りで保存するスコープ
+type Scopes []string
+
+func IsValidScope(scope string) bool {
+	return scope == ScopeRead || scope == ScopeWrite || scope == ScopeAdmin
+}
+
+// scopeの操作を許可しているか。上位のスコープは下位のスコープを含む
+func (scopes Scopes) Allows(scope string) bool {
+	for _, s := range scopes {
+		switch {
+		case s == scope,
+			s == ScopeAdmin,
+			s == ScopeWrite && scope == ScopeRead:
+			return true
+		}
+	}
+	return false
+}
+
+func (scopes Scopes) Value() (driver.Value, error) {
+	return strings.Join(scopes, " "), nil
+}
+
+func (scopes *Scopes) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		*scopes = strings.Fields(v)
+	case []byte:
+		*scopes = strings.Fields(string(v))
+	case nil:
+		*scopes = nil
+	default:
+		return fmt.Errorf("cannot scan %T into Scopes", value)
+	}
+	return nil
+}
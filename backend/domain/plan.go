@@ -0,0 +1,19 @@
+package domain
+
+// 空き時間に読む積読の計画
+type Plan struct {
+	FreeMinutes     int        `json:"freeMinutes"`
+	TotalMinutes    int        `json:"totalMinutes"`    // 計画した積読を読むのにかかる時間
+	LeftoverMinutes int        `json:"leftoverMinutes"` // 読み終わっても余る時間
+	Items           []PlanItem `json:"items"`           // 読む順
+	PagesPerMinute  float64    `json:"pagesPerMinute"`  // 本を何ページ読むか決めるのに使った速さ
+}
+
+type PlanItem struct {
+	Tsundoku
+	StartMinute int     `json:"startMinute"`       // 空き時間の始まりから何分後に読み始めるか
+	Minutes     int     `json:"minutes"`           // 読む時間
+	Pages       int     `json:"pages,omitempty"`   // ページ数の分かる本なら、読むページ数
+	EndPage     int     `json:"endPage,omitempty"` // どのページまで読むか
+	Value       float64 `json:"value"`             // 読む価値。大きいものほど計画に入りやすい
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// 1分間に読むページ数のデフォルト。読んだ時間の記録がまだ少ないときに使う
+const DefaultPagesPerMinute = 0.5
+
+// 本を読み進めた記録
+type ProgressLog struct {
+	ID         int       `gorm:"primary_key" json:"id"`
+	UserID     int       `gorm:"index" json:"userID"`
+	TsundokuID int       `gorm:"index" json:"tsundokuID"`
+	Page       int       `gorm:"not null" json:"page"`              // どのページまで読んだか
+	PagesRead  int       `gorm:"not null" json:"pagesRead"`         // 前の記録から進んだページ数。戻ったときは負
+	Minutes    int       `gorm:"not null;default:0" json:"minutes"` // 読んでいた時間(分)。0なら不明
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// 本の読み進め具合
+type Progress struct {
+	TsundokuID      int           `json:"tsundokuID"`
+	CurrentPage     int           `json:"currentPage"`
+	TotalPages      int           `json:"totalPages"`
+	PercentComplete int           `json:"percentComplete"`
+	Logs            []ProgressLog `json:"logs"` // 古い順
+}
+
+// 読んだページ数と時間の合計。読む速さを測る
+type ReadingPace struct {
+	Pages   int
+	Minutes int
+}
+
+// 1分間に読むページ数。記録が1時間に満たなければデフォルト
+func (pace ReadingPace) PagesPerMinute() float64 {
+	if pace.Minutes < 60 || pace.Pages <= 0 {
+		return DefaultPagesPerMinute
+	}
+	return float64(pace.Pages) / float64(pace.Minutes)
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "testing"
+
+func TestReadingPacePagesPerMinute(t *testing.T) {
+	tests := []struct {
+		name string
+		pace ReadingPace
+		want float64
+	}{
+		{"no records", ReadingPace{}, DefaultPagesPerMinute},
+		{"less than an hour", ReadingPace{Pages: 59, Minutes: 59}, DefaultPagesPerMinute},
+		{"an hour", ReadingPace{Pages: 60, Minutes: 60}, 1},
+		{"pages went back", ReadingPace{Pages: -10, Minutes: 90}, DefaultPagesPerMinute},
+		{"measured pace", ReadingPace{Pages: 45, Minutes: 90}, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pace.PagesPerMinute(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package domain
+
+import "time"
+
+// 文章の言語
+const (
+	LanguageJapanese = "ja"
+	LanguageEnglish  = "en"
+)
+
+// 一度の読書の時間の上限(分)。タイマーを止め忘れたときはここで打ち切る
+const MaxSessionMinutes = 12 * 60
+
+// 学んだ速さを使うのに必要な読書の時間(分)
+const MinLearnedSpeedMinutes = 30
+
+// 読む速さの単位
+const (
+	SpeedUnitChars = "chars"
+	SpeedUnitWords = "words"
+	SpeedUnitPages = "pages"
+)
+
+// 積読を読んだ時間の記録。EndedAtがnilならタイマーが動いている
+type ReadingSession struct {
+	ID               int        `gorm:"primary_key" json:"id"`
+	UserID           int        `gorm:"index" json:"userID"`
+	TsundokuID       int        `gorm:"index" json:"tsundokuID"`
+	StartedAt        time.Time  `gorm:"not null" json:"startedAt"`
+	EndedAt          *time.Time `json:"endedAt"`
+	Minutes          int        `gorm:"not null;default:0" json:"minutes"`          // 実際に読んだ時間
+	Pages            int        `gorm:"not null;default:0" json:"pages"`            // 本なら読み進めたページ数
+	EstimatedMinutes int        `gorm:"not null;default:0" json:"estimatedMinutes"` // 読み始めたときの積読の読む時間。0なら不明
+}
+
+// 積読ごとの読書の記録の合計。読む速さを学ぶのに使う
+type ReadingSample struct {
+	TsundokuID       int
+	Title            string
+	Category         string
+	Language         string
+	Status           string
+	TextChars        int
+	TextWords        int
+	Pages            int
+	Minutes          int
+	EstimatedMinutes int // 最初に読み始めたときの見積もり
+}
+
+// 記録から学んだ、カテゴリと言語ごとの読む速さ
+type LearnedSpeed struct {
+	Category  string  `json:"category"`
+	Language  string  `json:"language"`
+	Unit      string  `json:"unit"`      // chars、words、pages
+	PerMinute float64 `json:"perMinute"` // 1分間に読むUnitの数
+	Samples   int     `json:"samples"`   // 学んだ積読の数
+	Minutes   int     `json:"minutes"`   // 学んだ読書の時間の合計
+	Applied   bool    `json:"applied"`   // 記録が十分にあり、見積もりに使っているか
+}
+
+// 見積もった時間と実際に読んだ時間の比較
+type EstimateComparison struct {
+	TsundokuID       int     `json:"tsundokuID"`
+	Title            string  `json:"title"`
+	Category         string  `json:"category"`
+	EstimatedMinutes int     `json:"estimatedMinutes"`
+	ActualMinutes    int     `json:"actualMinutes"`
+	ErrorPercent     float64 `json:"errorPercent"` // 実際の時間に対する見積もりのずれ。長く見積もっていれば正
+}
+
+// 読み終わった積読の見積もりの正確さ
+type EstimateAccuracy struct {
+	Samples                  int                  `json:"samples"`
+	MeanAbsolutePercentError float64              `json:"meanAbsolutePercentError"`
+	Items                    []EstimateComparison `json:"items"`
+}
+
+type ReadingSpeedReport struct {
+	Speeds   []LearnedSpeed   `json:"speeds"`
+	Accuracy EstimateAccuracy `json:"accuracy"`
+}
+
+func IsValidLanguage(language string) bool {
+	return language == "" || language == LanguageJapanese || language == LanguageEnglish
+}
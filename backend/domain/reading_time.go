@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 読むのにかかる時間の上限(分)
+const MaxRequiredMinutes = 100 * 60
+
+// 英語などの文章を1分間に読む単語数のデフォルト
+const DefaultWordsPerMinute = 200
+
+// 時間の単位と、その単位が何分か
+var readingTimeUnits = []struct {
+	name    string
+	minutes float64
+}{
+	// 長いものから順に調べる
+	{"hours", 60}, {"hour", 60}, {"hrs", 60}, {"hr", 60}, {"h", 60}, {"時間", 60}, {"時", 60},
+	{"minutes", 1}, {"minute", 1}, {"mins", 1}, {"min", 1}, {"m", 1}, {"分", 1},
+}
+
+// 前後についていても無視する言葉
+var (
+	readingTimePrefixes = []string{"約", "およそ", "~", "〜"}
+	readingTimeSuffixes = []string{"くらい", "ぐらい", "程度", "ほど"}
+)
+
+// "90"、"1h30m"、"1時間半"、"30分"のような読むのにかかる時間を分にする。空文字なら0
+// 単位のない数字は分として扱う。ただし"1h30"のように時間のあとに続くときも分
+func ParseRequiredTime(text string) (int, error) {
+	s := normalizeReadingTime(text)
+	for _, prefix := range readingTimePrefixes {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	for _, suffix := range readingTimeSuffixes {
+		s = strings.TrimSuffix(s, suffix)
+	}
+	if s == "" {
+		return 0, nil
+	}
+
+	invalid := ValidationError("requiredTime must be like 90, 1h30m, 1時間半 or 30分")
+	total := 0.0
+	lastUnit := 0.0
+	for s != "" {
+		// 時間のあとの"半"は30分
+		if strings.HasPrefix(s, "半") {
+			if lastUnit != 60 {
+				return 0, invalid
+			}
+			total += 30
+			lastUnit = 0
+			s = strings.TrimPrefix(s, "半")
+			continue
+		}
+
+		end := strings.IndexFunc(s, func(r rune) bool { return !(r >= '0' && r <= '9' || r == '.') })
+		if end == 0 {
+			return 0, invalid
+		}
+		if end < 0 {
+			end = len(s)
+		}
+		value, err := strconv.ParseFloat(s[:end], 64)
+		if err != nil {
+			return 0, invalid
+		}
+		s = s[end:]
+
+		unit := 0.0
+		for _, u := range readingTimeUnits {
+			if strings.HasPrefix(s, u.name) {
+				unit = u.minutes
+				s = strings.TrimPrefix(s, u.name)
+				break
+			}
+		}
+		if unit == 0 {
+			// 単位を省略できるのは最後の数字だけ
+			if s != "" {
+				return 0, invalid
+			}
+			unit = 1
+		}
+		total += value * unit
+		lastUnit = unit
+	}
+
+	minutes := int(math.Round(total))
+	if minutes > MaxRequiredMinutes {
+		return 0, ValidationError("requiredTime is too long")
+	}
+	return minutes, nil
+}
+
+// 全角の英数字を半角にし、小文字にして空白を除く
+func normalizeReadingTime(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if r >= '！' && r <= '～' {
+			r -= '！' - '!'
+		}
+		if unicode.IsSpace(r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// 分を"1時間30分"のような表示にする。0なら空文字
+func FormatRequiredTime(minutes int) string {
+	if minutes <= 0 {
+		return ""
+	}
+	hours, minutes := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return strconv.Itoa(minutes) + "分"
+	case minutes == 0:
+		return strconv.Itoa(hours) + "時間"
+	}
+	return strconv.Itoa(hours) + "時間" + strconv.Itoa(minutes) + "分"
+}
@@ -0,0 +1,16 @@
+package domain
+
+// おすすめした理由
+const (
+	ReasonDeadline = "deadline" // 締め切りが近い、または過ぎている
+	ReasonStale    = "stale"    // 積んでから長い
+	ReasonQuick    = "quick"    // すぐ読める
+	ReasonRelated  = "related"  // 最近読んだ積読とタグが同じ
+	ReasonReading  = "reading"  // 読みかけ
+)
+
+type Recommendation struct {
+	Tsundoku
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
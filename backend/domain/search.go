@@ -0,0 +1,8 @@
+package domain
+
+// 検索でヒットした積読
+type SearchResult struct {
+	Tsundoku
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights" gorm:"-"`
+}
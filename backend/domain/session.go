@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// ログインした端末ごとのセッション。リフレッシュトークンはハッシュだけを保存する
+type Session struct {
+	ID        int       `gorm:"primary_key" json:"id"`
+	UserID    int       `gorm:"not null;index" json:"userID"`
+	TokenHash string    `gorm:"not null;unique_index" json:"-"`
+	ExpiresAt time.Time `gorm:"not null" json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// APIの認証に使うトークン
+// アクセストークンの期限が切れたらリフレッシュトークンで新しいトークンを発行する
+type SessionToken struct {
+	AccessToken           string    `json:"accessToken"`
+	AccessTokenExpiresAt  time.Time `json:"accessTokenExpiresAt"`
+	RefreshToken          string    `json:"refreshToken"`
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+}
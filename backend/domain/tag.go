@@ -1,6 +1,11 @@
 package domain
 
+import "time"
+
+// 名前はユーザーごとに一意(ゴミ箱に入っているものは除く)
 type Tag struct {
-	ID   int    `gorm:"primary_key" json:"id"`
-	Name string `gorm:"not null" json:"name"`
+	ID        int        `gorm:"primary_key" json:"id"`
+	UserID    int        `gorm:"index" json:"userID"`
+	Name      string     `gorm:"not null" json:"name"`
+	DeletedAt *time.Time `gorm:"index" json:"deletedAt,omitempty"` // ゴミ箱に入れた時刻
 }
@@ -0,0 +1,7 @@
+package domain
+
+// ゴミ箱に入っている積読とタグ
+type Trash struct {
+	Tsundokus []Tsundoku `json:"tsundokus"`
+	Tags      []Tag      `json:"tags"`
+}
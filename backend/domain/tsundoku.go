@@ -2,15 +2,148 @@ package domain
 
 import "time"
 
+// 積読のカテゴリ
+const (
+	CategoryBook = "book"
+	CategorySite = "site"
+)
+
+// 積読の優先度。大きいほど優先する
+const (
+	PriorityLowest  = 1
+	PriorityNormal  = 3
+	PriorityHighest = 5
+)
+
+// 積読の読書状態
+const (
+	StatusUnread    = "unread"
+	StatusReading   = "reading"
+	StatusDone      = "done"
+	StatusAbandoned = "abandoned"
+)
+
+// まだ読み終わっていない状態
+var UnfinishedStatuses = []string{StatusUnread, StatusReading}
+
+// 状態ごとに遷移できる先の状態
+var statusTransitions = map[string][]string{
+	StatusUnread:    {StatusReading, StatusDone, StatusAbandoned},
+	StatusReading:   {StatusDone, StatusAbandoned},
+	StatusAbandoned: {StatusReading},
+	StatusDone:      {},
+}
+
 type Tsundoku struct {
-	ID           int       `gorm:"primary_key" json:"id"`
-	UserID       int       `json:"userID"`
-	Category     string    `gorm:"not null" json:"category"`
-	Title        string    `gorm:"not null" json:"title"`
-	Author       string    `json:"author"`
-	URL          string    `json:"url"`
-	Deadline     time.Time `json:"deadline"`
-	RequiredTime string    `json:"requiredTime"`
-	CreatedAt    time.Time `json:"createdAt"`
-	Tags         []Tag     `gorm:"-" json:"tags"` // このフィールドは無視
+	ID                    int        `gorm:"primary_key" json:"id"`
+	UserID                int        `json:"userID"`
+	Category              string     `gorm:"not null" json:"category"`
+	Title                 string     `gorm:"not null" json:"title"`
+	Author                string     `json:"author"`
+	URL                   string     `json:"url"`
+	Note                  string     `json:"note"`
+	Deadline              time.Time  `json:"deadline"`
+	RequiredMinutes       int        `gorm:"not null;default:0" json:"requiredMinutes"`           // 読むのにかかる時間(分)。0なら不明
+	RequiredTime          string     `gorm:"-" json:"requiredTime"`                               // RequiredMinutesを"1時間30分"のようにしたもの
+	RequiredTimeEstimated bool       `gorm:"not null;default:false" json:"requiredTimeEstimated"` // ページの文章から見積もった時間か。ユーザーが入力したら外れる
+	EstimateAttempts      int        `gorm:"not null;default:0" json:"-"`                         // 見積もりに失敗した回数
+	EstimateCheckedAt     *time.Time `json:"-"`                                                   // 最後に見積もろうとした時刻
+	Priority              int        `gorm:"not null;default:3" json:"priority"`
+	Language              string     `gorm:"not null;default:''" json:"language"`   // jaかen。サイトはページの文章から決める
+	TextChars             int        `gorm:"not null;default:0" json:"-"`           // ページの本文の文字数
+	TextWords             int        `gorm:"not null;default:0" json:"-"`           // ページの本文の単語数
+	TotalPages            int        `gorm:"not null;default:0" json:"totalPages"`  // 本のページ数。0なら不明
+	CurrentPage           int        `gorm:"not null;default:0" json:"currentPage"` // どのページまで読んだか
+	PercentComplete       int        `gorm:"-" json:"percentComplete"`              // CurrentPageがTotalPagesの何%か
+	Status                string     `gorm:"not null;default:'unread'" json:"status"`
+	StartedAt             *time.Time `json:"startedAt"`
+	FinishedAt            *time.Time `json:"finishedAt"`
+	AbandonedAt           *time.Time `json:"abandonedAt"`
+	CreatedAt             time.Time  `json:"createdAt"`
+	DeletedAt             *time.Time `gorm:"index" json:"deletedAt,omitempty"` // ゴミ箱に入れた時刻
+	Tags                  []Tag      `gorm:"-" json:"tags"`                    // このフィールドは無視
+}
+
+// データベースから読んだときと保存したときに、表示用の読む時間と読み進め具合を埋める
+func (tsundoku *Tsundoku) AfterFind() error {
+	tsundoku.RequiredTime = FormatRequiredTime(tsundoku.RequiredMinutes)
+	tsundoku.PercentComplete = tsundoku.progressPercent()
+	return nil
+}
+
+func (tsundoku *Tsundoku) AfterSave() error {
+	return tsundoku.AfterFind()
+}
+
+// 読む時間を見積もっていない状態に戻す。RequiredMinutesが0なら改めて見積もる
+func (tsundoku *Tsundoku) ResetEstimate() {
+	tsundoku.RequiredTimeEstimated = false
+	tsundoku.EstimateAttempts = 0
+	tsundoku.EstimateCheckedAt = nil
+}
+
+// ページ数が分からなければ0
+func (tsundoku Tsundoku) progressPercent() int {
+	if tsundoku.TotalPages <= 0 {
+		return 0
+	}
+	return tsundoku.CurrentPage * 100 / tsundoku.TotalPages
+}
+
+// 読み残しているページ数。ページ数が分からなければ0
+func (tsundoku Tsundoku) RemainingPages() int {
+	if tsundoku.TotalPages <= 0 || tsundoku.CurrentPage >= tsundoku.TotalPages {
+		return 0
+	}
+	return tsundoku.TotalPages - tsundoku.CurrentPage
+}
+
+// 締め切りなしはゼロ値
+func (tsundoku Tsundoku) HasDeadline() bool {
+	return tsundoku.Deadline.After(time.Time{}.AddDate(0, 0, 1))
+}
+
+func IsValidCategory(category string) bool {
+	return category == CategoryBook || category == CategorySite
+}
+
+func IsValidPriority(priority int) bool {
+	return priority >= PriorityLowest && priority <= PriorityHighest
+}
+
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// 読書状態を遷移させ、遷移した時刻を記録する
+func (tsundoku *Tsundoku) ChangeStatus(status string, now time.Time) error {
+	allowed := false
+	for _, next := range statusTransitions[tsundoku.Status] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return ErrInvalidStatusTransition
+	}
+
+	switch status {
+	case StatusReading:
+		// 途中で投げ出したものを再開したときは最初に読み始めた時刻を残す
+		if tsundoku.StartedAt == nil {
+			tsundoku.StartedAt = &now
+		}
+		tsundoku.AbandonedAt = nil
+	case StatusDone:
+		if tsundoku.StartedAt == nil {
+			tsundoku.StartedAt = &now
+		}
+		tsundoku.FinishedAt = &now
+	case StatusAbandoned:
+		tsundoku.AbandonedAt = &now
+	}
+	tsundoku.Status = status
+	return nil
 }
@@ -1,7 +1,10 @@
 package domain
 
+import "time"
+
 type TsundokuTag struct {
-	TsundokuID int `gorm:"primary_key" json:"tsundokuID"`
-	TagID      int `gorm:"primary_key" json:"tagID"`
-	UserID     int `json:"userID"`
+	TsundokuID int        `gorm:"primary_key" json:"tsundokuID"`
+	TagID      int        `gorm:"primary_key" json:"tagID"`
+	UserID     int        `json:"userID"`
+	DeletedAt  *time.Time `gorm:"index" json:"-"` // 積読やタグと一緒にゴミ箱に入れた時刻
 }
@@ -4,10 +4,66 @@ import (
 	"time"
 )
 
+// ユーザーの役割
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// ユーザーが設定していないときのタイムゾーン
+const DefaultTimezone = "Asia/Tokyo"
+
+// ユーザーが設定していないときの読む速さ(1分間に読む文字数)
+const DefaultReadingSpeed = 500
+
 type User struct {
-	ID        int       `gorm:"primary_key" json:"id"`
-	Name      string    `gorm:"not null" json:"name"`
-	LINEID    string    `json:"lineID"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID      int    `gorm:"primary_key" json:"id"`
+	Name    string `gorm:"not null" json:"name"`
+	Email   string `json:"email"`
+	Picture string `json:"picture"`
+	// LINEのステータスメッセージ
+	StatusMessage string `json:"statusMessage"`
+	// 名前とアイコンをユーザーが変えたら、ログインしてもIDプロバイダーのものに戻さない
+	NameOverridden    bool   `gorm:"not null;default:false" json:"nameOverridden"`
+	PictureOverridden bool   `gorm:"not null;default:false" json:"pictureOverridden"`
+	Timezone          string `gorm:"not null;default:'Asia/Tokyo'" json:"timezone"`
+	// 1分間に読む文字数。0なら未設定
+	ReadingSpeed int    `gorm:"not null;default:0" json:"readingSpeed"`
+	Role         string `gorm:"not null;default:'user'" json:"role"`
+	// 退会を予約したときの削除する日時。それまでにログインすれば取り消される
+	DeletionScheduledAt *time.Time `gorm:"index" json:"deletionScheduledAt,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+}
+
+func (u User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// 未設定ならDefaultReadingSpeed
+func (u User) ReadingSpeedOrDefault() int {
+	if u.ReadingSpeed <= 0 {
+		return DefaultReadingSpeed
+	}
+	return u.ReadingSpeed
+}
+
+// IDプロバイダーから取得したプロフィールを反映して、変わったかを返す
+// 空の項目と、ユーザーが変えた名前とアイコンはそのままにする
+func (u *User) SyncProfile(profile User) bool {
+	changed := false
+	sync := func(field *string, value string, overridden bool) {
+		if value != "" && value != *field && !overridden {
+			*field, changed = value, true
+		}
+	}
+	sync(&u.Name, profile.Name, u.NameOverridden)
+	sync(&u.Picture, profile.Picture, u.PictureOverridden)
+	sync(&u.Email, profile.Email, false)
+	sync(&u.StatusMessage, profile.StatusMessage, false)
+	return changed
+}
+
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
 }
@@ -0,0 +1,270 @@
+// ネットワークにつながずにログインを試すための偽のIDプロバイダー
+// LINEログインと同じAPIとOpenID Connectのエンドポイントを持つ
+// cmd/fakeidpで起動するほか、テストではhttptestのサーバーで動かす
+// IDトークンは作るたびに生成するES256の鍵で署名する
+package fakeidp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// IDトークンの署名に使う鍵のID
+const keyID = "fakeidp"
+
+// 発行したアクセストークンの有効期間
+const tokenTTL = time.Hour
+
+type user struct {
+	Sub     string `json:"sub"`
+	Name    string `json:"name"`
+	Picture string `json:"picture,omitempty"`
+	Email   string `json:"email,omitempty"`
+	// LINEのステータスメッセージ。/v2/profileだけで返す
+	StatusMessage string `json:"-"`
+}
+
+type token struct {
+	user      user
+	clientID  string
+	expiresAt time.Time
+}
+
+// http.Handlerとして使う
+type Server struct {
+	issuer   string
+	clientID string
+	key      *ecdsa.PrivateKey
+	mux      *http.ServeMux
+
+	mu     sync.Mutex
+	tokens map[string]token
+}
+
+// issuerはIDトークンのissと、ディスカバリーで返すエンドポイントのURL
+// clientIDはLINEのアクセストークンの検証で返すclient_idとIDトークンのaud
+func New(issuer string, clientID string) (*Server, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		issuer:   strings.TrimRight(issuer, "/"),
+		clientID: clientID,
+		key:      key,
+		tokens:   map[string]token{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", s.issueToken)
+	// OpenID Connect
+	mux.HandleFunc("/.well-known/openid-configuration", s.configuration)
+	mux.HandleFunc("/jwks", s.jwks)
+	mux.HandleFunc("/userinfo", s.userinfo)
+	mux.HandleFunc("/revoke", s.revoke)
+	mux.HandleFunc("/introspect", s.introspect)
+	// LINEログイン
+	mux.HandleFunc("/oauth2/v2.1/certs", s.jwks)
+	mux.HandleFunc("/oauth2/v2.1/verify", s.lineVerify)
+	mux.HandleFunc("/v2/profile", s.lineProfile)
+	mux.HandleFunc("/oauth2/v2.1/revoke", s.revoke)
+	s.mux = mux
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// subのユーザーのアクセストークンとIDトークンを発行する。name、email、picture、status_message、nonceも指定できる
+// client_idを指定すると、Newで渡したものの代わりにそのクライアントに発行する
+func (s *Server) issueToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	u := user{
+		Sub:           r.FormValue("sub"),
+		Name:          r.FormValue("name"),
+		Picture:       r.FormValue("picture"),
+		Email:         r.FormValue("email"),
+		StatusMessage: r.FormValue("status_message"),
+	}
+	if u.Sub == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": "sub is required"})
+		return
+	}
+	if u.Name == "" {
+		u.Name = u.Sub
+	}
+	clientID := r.FormValue("client_id")
+	if clientID == "" {
+		clientID = s.clientID
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	accessToken := hex.EncodeToString(b)
+	now := time.Now()
+	s.mu.Lock()
+	s.tokens[accessToken] = token{user: u, clientID: clientID, expiresAt: now.Add(tokenTTL)}
+	s.mu.Unlock()
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss":     s.issuer,
+		"sub":     u.Sub,
+		"aud":     clientID,
+		"exp":     now.Add(tokenTTL).Unix(),
+		"iat":     now.Unix(),
+		"nonce":   r.FormValue("nonce"),
+		"amr":     []string{"pwd"},
+		"name":    u.Name,
+		"picture": u.Picture,
+		"email":   u.Email,
+	})
+	idToken.Header["kid"] = keyID
+	signed, err := idToken.SignedString(s.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"id_token":     signed,
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenTTL.Seconds()),
+	})
+}
+
+func (s *Server) configuration(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                   s.issuer,
+		"token_endpoint":           s.issuer + "/token",
+		"userinfo_endpoint":        s.issuer + "/userinfo",
+		"jwks_uri":                 s.issuer + "/jwks",
+		"revocation_endpoint":      s.issuer + "/revoke",
+		"introspection_endpoint":   s.issuer + "/introspect",
+		"response_types_supported": []string{"code"},
+		"subject_types_supported":  []string{"public"},
+	})
+}
+
+// IDトークンを検証するための公開鍵
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": keyID,
+			"kty": "EC",
+			"crv": "P-256",
+			"alg": "ES256",
+			"use": "sig",
+			"x":   base64.RawURLEncoding.EncodeToString(s.key.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(s.key.Y.FillBytes(make([]byte, 32))),
+		}},
+	})
+}
+
+func (s *Server) userinfo(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.lookup(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, t.user)
+}
+
+// OpenID ConnectのtokenとLINEのaccess_tokenのどちらでも受け付ける
+func (s *Server) revoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	accessToken := r.FormValue("token")
+	if accessToken == "" {
+		accessToken = r.FormValue("access_token")
+	}
+	s.mu.Lock()
+	delete(s.tokens, accessToken)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+// RFC 7662のイントロスペクション。無効なトークンはactive: falseだけを返す
+func (s *Server) introspect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	t, ok := s.lookup(r.FormValue("token"))
+	if !ok {
+		writeJSON(w, http.StatusOK, map[string]bool{"active": false})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"active":     true,
+		"client_id":  t.clientID,
+		"sub":        t.user.Sub,
+		"token_type": "Bearer",
+		"exp":        t.expiresAt.Unix(),
+	})
+}
+
+func (s *Server) lineVerify(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.lookup(r.FormValue("access_token"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": "access token expired"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"scope":      "profile openid",
+		"client_id":  t.clientID,
+		"expires_in": int(time.Until(t.expiresAt).Seconds()),
+	})
+}
+
+func (s *Server) lineProfile(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.lookup(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "invalid token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"userId":        t.user.Sub,
+		"displayName":   t.user.Name,
+		"pictureUrl":    t.user.Picture,
+		"statusMessage": t.user.StatusMessage,
+	})
+}
+
+// 有効なアクセストークンを探す
+func (s *Server) lookup(accessToken string) (token, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[accessToken]
+	if !ok || time.Now().After(t.expiresAt) {
+		return token{}, false
+	}
+	return t, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,48 @@
+package infrastructure
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+)
+
+// 退会を予約すると個人用アクセストークンは使えなくなり、取り消すとまた使える
+func TestPersonalAccessTokenWhileDeletionScheduled(t *testing.T) {
+	db := openTestSchema(t, "test_account")
+	user := domain.User{Name: "alice"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	token := domain.PersonalAccessToken{UserID: user.ID, Name: "script", TokenHash: "hash", Scopes: domain.Scopes{domain.ScopeRead}}
+	if err := db.Create(&token).Error; err != nil {
+		t.Fatal(err)
+	}
+	handler := &SqlHandler{db: db}
+	accounts := &database.AccountRepository{SqlHandler: handler}
+	tokens := &database.PersonalAccessTokenRepository{SqlHandler: handler}
+	users := &database.UserRepository{SqlHandler: handler}
+
+	if err := accounts.ScheduleDeletion(user.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.SelectByTokenHash("hash"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("scheduled: got error %v, want %v", err, domain.ErrNotFound)
+	}
+	if _, err := tokens.SelectByID(token.ID); err != nil {
+		t.Errorf("token was deleted before the purge: %v", err)
+	}
+
+	if err := users.CancelDeletion(user.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err := tokens.SelectByTokenHash("hash")
+	if err != nil {
+		t.Fatalf("cancelled: unexpected error: %v", err)
+	}
+	if got.ID != token.ID {
+		t.Errorf("got token %d, want %d", got.ID, token.ID)
+	}
+}
@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// エラーレスポンスの形式
+// {"error": {"code": "not_found", "message": "tsundoku not found"}}
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var statusByKind = map[string]int{
+	domain.ErrorKindUnauthorized: http.StatusUnauthorized,
+	domain.ErrorKindNotFound:     http.StatusNotFound,
+	domain.ErrorKindForbidden:    http.StatusForbidden,
+	domain.ErrorKindConflict:     http.StatusConflict,
+	domain.ErrorKindValidation:   http.StatusBadRequest,
+	domain.ErrorKindInternal:     http.StatusInternalServerError,
+}
+
+// echoのHTTPErrorのステータスコードに対応するcode
+var kindByStatus = map[int]string{
+	http.StatusBadRequest:           domain.ErrorKindValidation,
+	http.StatusUnauthorized:         domain.ErrorKindUnauthorized,
+	http.StatusForbidden:            domain.ErrorKindForbidden,
+	http.StatusNotFound:             domain.ErrorKindNotFound,
+	http.StatusMethodNotAllowed:     "method_not_allowed",
+	http.StatusConflict:             domain.ErrorKindConflict,
+	http.StatusUnsupportedMediaType: "unsupported_media_type",
+}
+
+// domain.Errorとecho.HTTPErrorを同じ形式のJSONで返す
+func errorHandler(err error, c echo.Context) {
+	status := http.StatusInternalServerError
+	res := errorResponse{Error: errorBody{
+		Code:    domain.ErrorKindInternal,
+		Message: "internal server error",
+	}}
+
+	var domainErr *domain.Error
+	var httpErr *echo.HTTPError
+	switch {
+	case errors.As(err, &domainErr):
+		status = statusByKind[domainErr.Kind]
+		res.Error.Code = domainErr.Kind
+		// 内部のエラーの詳細はクライアントに返さない
+		if domainErr.Kind != domain.ErrorKindInternal {
+			res.Error.Message = domainErr.Message
+		}
+	case errors.As(err, &httpErr):
+		status = httpErr.Code
+		if kind, ok := kindByStatus[status]; ok {
+			res.Error.Code = kind
+		}
+		res.Error.Message = fmt.Sprint(httpErr.Message)
+	}
+
+	if status >= http.StatusInternalServerError {
+		c.Logger().Error(err)
+	}
+	if c.Response().Committed {
+		return
+	}
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = c.JSON(status, res)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
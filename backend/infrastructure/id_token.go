@@ -0,0 +1,237 @@
+package infrastructure
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/yot-sailing/TSUNTSUN/body"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+const (
+	// 公開鍵を取り直すまでの時間
+	jwksCacheTTL = 24 * time.Hour
+	// 知らない鍵IDのトークンが来たときに公開鍵を取り直す間隔の最小値
+	jwksRefreshInterval = time.Minute
+	// サーバー間の時計のずれの許容範囲
+	idTokenLeeway = time.Minute
+)
+
+// IDトークンをIDプロバイダーに問い合わせずに検証する
+// ES256とRS256は公開鍵(JWKS)で、HS256はチャネルシークレットで署名を確かめる
+type idTokenVerifier struct {
+	issuer   string
+	audience string
+	secret   string // HS256の鍵。空ならHS256は受け付けない
+	jwks     *jwksCache
+}
+
+// IDトークンのペイロード。audは文字列か配列
+type idTokenClaims struct {
+	body.VerifyResponseBody
+	Aud audience `json:"aud"`
+}
+
+type audience []string
+
+func (aud *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*aud = audience{s}
+		return nil
+	}
+	var ss []string
+	if err := json.Unmarshal(b, &ss); err != nil {
+		return err
+	}
+	*aud = ss
+	return nil
+}
+
+func (aud audience) contains(s string) bool {
+	for _, a := range aud {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
+
+// 期限はjwt-goがパースしたあとに呼ぶ
+func (claims *idTokenClaims) Valid() error {
+	now := time.Now()
+	if claims.Exp == 0 || now.After(time.Unix(int64(claims.Exp), 0).Add(idTokenLeeway)) {
+		return fmt.Errorf("token is expired")
+	}
+	if claims.Iat != 0 && now.Add(idTokenLeeway).Before(time.Unix(int64(claims.Iat), 0)) {
+		return fmt.Errorf("token used before issued")
+	}
+	return nil
+}
+
+// 署名、iss、aud、exp、nonceを確かめて、ペイロードを返す
+func (verifier *idTokenVerifier) Verify(idToken string, nonce string) (body.VerifyResponseBody, error) {
+	claims := idTokenClaims{}
+	_, err := jwt.ParseWithClaims(idToken, &claims, verifier.key)
+	if err != nil {
+		// 公開鍵を取得できなかったときはトークンが悪いわけではない
+		// jwt-goのValidationErrorはUnwrapできない
+		var domainErr *domain.Error
+		if validationErr, ok := err.(*jwt.ValidationError); ok && errors.As(validationErr.Inner, &domainErr) && domainErr.Kind == domain.ErrorKindInternal {
+			return body.VerifyResponseBody{}, domainErr
+		}
+		return body.VerifyResponseBody{}, domain.UnauthorizedError("invalid id token")
+	}
+	if claims.Iss != verifier.issuer {
+		return body.VerifyResponseBody{}, domain.UnauthorizedError("id token was issued by another issuer")
+	}
+	if verifier.audience == "" || !claims.Aud.contains(verifier.audience) {
+		return body.VerifyResponseBody{}, domain.UnauthorizedError("id token was issued for another client")
+	}
+	// ログインを始めたクライアントが作ったnonceと同じでなければ、盗まれたトークンを使い回されている
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return body.VerifyResponseBody{}, domain.UnauthorizedError("nonce does not match")
+	}
+	if claims.Sub == "" {
+		return body.VerifyResponseBody{}, domain.UnauthorizedError("id token has no subject")
+	}
+	res := claims.VerifyResponseBody
+	res.Aud = verifier.audience
+	return res, nil
+}
+
+func profileFromIDToken(claims body.VerifyResponseBody) usecase.ProviderProfile {
+	return usecase.ProviderProfile{
+		Subject: claims.Sub,
+		Name:    claims.Name,
+		Picture: claims.Picture,
+		Email:   claims.Email,
+	}
+}
+
+// トークンの署名方式に合う鍵を返す。alg=noneなど想定していない方式は受け付けない
+func (verifier *idTokenVerifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method {
+	case jwt.SigningMethodHS256:
+		if verifier.secret == "" {
+			return nil, fmt.Errorf("HS256 is not allowed")
+		}
+		return []byte(verifier.secret), nil
+	case jwt.SigningMethodES256, jwt.SigningMethodRS256:
+		if verifier.jwks == nil {
+			return nil, fmt.Errorf("%s is not allowed", token.Method.Alg())
+		}
+		kid, _ := token.Header["kid"].(string)
+		return verifier.jwks.key(kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+}
+
+// JWKSの公開鍵を鍵IDごとにキャッシュする
+type jwksCache struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+func newJWKSCache(url string, client *http.Client) *jwksCache {
+	return &jwksCache{url: url, client: client}
+}
+
+func (cache *jwksCache) key(kid string) (interface{}, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	key, ok := cache.keys[kid]
+	expired := time.Since(cache.fetchedAt) > jwksCacheTTL
+	// 鍵がローテーションされたかもしれないので、知らない鍵IDなら取り直す
+	if (!ok && time.Since(cache.fetchedAt) > jwksRefreshInterval) || expired {
+		if err := cache.fetch(); err != nil {
+			return nil, err
+		}
+		key, ok = cache.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+	return key, nil
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (cache *jwksCache) fetch() error {
+	req, err := http.NewRequest(http.MethodGet, cache.url, nil)
+	if err != nil {
+		return domain.InternalError(err)
+	}
+	res := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	if err := doProviderRequest(cache.client, req, &res); err != nil {
+		return domain.InternalError(err)
+	}
+	keys := map[string]interface{}{}
+	for _, k := range res.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			// 使えない鍵があっても他の鍵は使う。その鍵で署名されたトークンはkeyで見つからずに401になる
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	cache.keys = keys
+	cache.fetchedAt = time.Now()
+	return nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch {
+	case k.Kty == "EC" && k.Crv == "P-256":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("point is not on curve")
+		}
+		return key, nil
+	case k.Kty == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type: %s %s", k.Kty, k.Crv)
+}
@@ -0,0 +1,105 @@
+package infrastructure
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/yot-sailing/TSUNTSUN/body"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+// LINEログイン
+// LINE_API_BASE_URLを変えるとcmd/fakeidpなどLINEと同じAPIを持つサーバーを使える
+type lineProvider struct {
+	baseURL       string
+	channelID     string
+	channelSecret string
+	client        *http.Client
+	idToken       *idTokenVerifier
+}
+
+// LINEのアクセストークンの検証結果
+type lineVerifyResponse struct {
+	Scope     string `json:"scope"`
+	ClientID  string `json:"client_id"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// LINE_ID_TOKEN_ISSUERはcmd/fakeidpを使うときだけ変える
+func newLINEProvider(client *http.Client) *lineProvider {
+	provider := &lineProvider{
+		baseURL:       baseURL("LINE_API_BASE_URL", "https://api.line.me"),
+		channelID:     os.Getenv("CHANNEL_ID"),
+		channelSecret: os.Getenv("CHANNEL_SECRET"),
+		client:        client,
+	}
+	provider.idToken = &idTokenVerifier{
+		issuer:   baseURL("LINE_ID_TOKEN_ISSUER", "https://access.line.me"),
+		audience: provider.channelID,
+		secret:   provider.channelSecret,
+		jwks:     newJWKSCache(provider.baseURL+"/oauth2/v2.1/certs", client),
+	}
+	return provider
+}
+
+func (provider *lineProvider) Name() string {
+	return "line"
+}
+
+func (provider *lineProvider) VerifyAccessToken(accessToken string) error {
+	req, err := http.NewRequest(http.MethodGet, provider.baseURL+"/oauth2/v2.1/verify?access_token="+url.QueryEscape(accessToken), nil)
+	if err != nil {
+		return domain.InternalError(err)
+	}
+	res := lineVerifyResponse{}
+	if err := doProviderRequest(provider.client, req, &res); err != nil {
+		return err
+	}
+	// 他のチャネルで発行されたアクセストークンは受け付けない
+	if provider.channelID != "" && res.ClientID != provider.channelID {
+		return domain.UnauthorizedError("access token was issued for another channel")
+	}
+	return nil
+}
+
+func (provider *lineProvider) Profile(accessToken string) (usecase.ProviderProfile, error) {
+	req, err := http.NewRequest(http.MethodGet, provider.baseURL+"/v2/profile", nil)
+	if err != nil {
+		return usecase.ProviderProfile{}, domain.InternalError(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	lineUser := body.LINEUser{}
+	if err := doProviderRequest(provider.client, req, &lineUser); err != nil {
+		return usecase.ProviderProfile{}, err
+	}
+	return usecase.ProviderProfile{
+		Subject:       lineUser.UserID,
+		Name:          lineUser.DisplayName,
+		Picture:       lineUser.PictureUrl,
+		StatusMessage: lineUser.StatusMessage,
+	}, nil
+}
+
+func (provider *lineProvider) VerifyIDToken(idToken string, nonce string) (usecase.ProviderProfile, error) {
+	claims, err := provider.idToken.Verify(idToken, nonce)
+	if err != nil {
+		return usecase.ProviderProfile{}, err
+	}
+	return profileFromIDToken(claims), nil
+}
+
+func (provider *lineProvider) Revoke(accessToken string) error {
+	form := url.Values{}
+	form.Set("client_id", provider.channelID)
+	form.Set("client_secret", provider.channelSecret)
+	form.Set("access_token", accessToken)
+	req, err := http.NewRequest(http.MethodPost, provider.baseURL+"/oauth2/v2.1/revoke", strings.NewReader(form.Encode()))
+	if err != nil {
+		return domain.InternalError(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doProviderRequest(provider.client, req, nil)
+}
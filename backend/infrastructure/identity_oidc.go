@@ -0,0 +1,185 @@
+package infrastructure
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+// OpenID Connectに対応したIDプロバイダー
+// OIDC_ISSUERのディスカバリーでエンドポイントを調べ、アクセストークンはイントロスペクションで確認する
+type oidcProvider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+
+	mu      sync.Mutex
+	config  *oidcConfiguration // 取得できるまで毎回取りに行く
+	idToken *idTokenVerifier
+}
+
+// /.well-known/openid-configurationのうち使うもの
+type oidcConfiguration struct {
+	Issuer                string `json:"issuer"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	RevocationEndpoint    string `json:"revocation_endpoint"`
+	IntrospectionEndpoint string `json:"introspection_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// RFC 7662のイントロスペクションの結果のうち使うもの
+type oidcIntrospection struct {
+	Active   bool     `json:"active"`
+	ClientID string   `json:"client_id"`
+	Aud      audience `json:"aud"`
+}
+
+type oidcUserinfo struct {
+	Sub     string `json:"sub"`
+	Name    string `json:"name"`
+	Picture string `json:"picture"`
+	Email   string `json:"email"`
+}
+
+func newOIDCProvider(client *http.Client) *oidcProvider {
+	return &oidcProvider{
+		issuer:       baseURL("OIDC_ISSUER", ""),
+		clientID:     os.Getenv("OIDC_CLIENT_ID"),
+		clientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		client:       client,
+	}
+}
+
+func (provider *oidcProvider) Name() string {
+	return "oidc"
+}
+
+func (provider *oidcProvider) configuration() (*oidcConfiguration, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if provider.config != nil {
+		return provider.config, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, provider.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, domain.InternalError(err)
+	}
+	config := oidcConfiguration{}
+	if err := doProviderRequest(provider.client, req, &config); err != nil {
+		return nil, domain.InternalError(err)
+	}
+	// 別のIDプロバイダーの設定を返されても使わない
+	if strings.TrimRight(config.Issuer, "/") != provider.issuer {
+		return nil, domain.InternalError(fmt.Errorf("issuer mismatch: %s", config.Issuer))
+	}
+	provider.config = &config
+	provider.idToken = &idTokenVerifier{
+		issuer:   config.Issuer,
+		audience: provider.clientID,
+		secret:   provider.clientSecret,
+	}
+	if config.JWKSURI != "" {
+		provider.idToken.jwks = newJWKSCache(config.JWKSURI, provider.client)
+	}
+	return provider.config, nil
+}
+
+func (provider *oidcProvider) userinfo(accessToken string) (oidcUserinfo, error) {
+	config, err := provider.configuration()
+	if err != nil {
+		return oidcUserinfo{}, err
+	}
+	req, err := http.NewRequest(http.MethodGet, config.UserinfoEndpoint, nil)
+	if err != nil {
+		return oidcUserinfo{}, domain.InternalError(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	userinfo := oidcUserinfo{}
+	err = doProviderRequest(provider.client, req, &userinfo)
+	return userinfo, err
+}
+
+// UserInfoエンドポイントは他のクライアントに発行されたアクセストークンでも答えるので、
+// イントロスペクションでこのクライアントに発行されたものか確かめる
+// イントロスペクションのないプロバイダーではIDトークンでログインしてもらう
+func (provider *oidcProvider) VerifyAccessToken(accessToken string) error {
+	config, err := provider.configuration()
+	if err != nil {
+		return err
+	}
+	if config.IntrospectionEndpoint == "" {
+		return domain.UnauthorizedError("access token login is not supported by this provider, use an id token")
+	}
+	form := url.Values{}
+	form.Set("token", accessToken)
+	form.Set("token_type_hint", "access_token")
+	req, err := http.NewRequest(http.MethodPost, config.IntrospectionEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return domain.InternalError(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(provider.clientID, provider.clientSecret)
+	res := oidcIntrospection{}
+	if err := doProviderRequest(provider.client, req, &res); err != nil {
+		return err
+	}
+	if !res.Active {
+		return domain.UnauthorizedError("invalid access token")
+	}
+	if provider.clientID == "" || (res.ClientID != provider.clientID && !res.Aud.contains(provider.clientID)) {
+		return domain.UnauthorizedError("access token was issued for another client")
+	}
+	return nil
+}
+
+func (provider *oidcProvider) Profile(accessToken string) (usecase.ProviderProfile, error) {
+	userinfo, err := provider.userinfo(accessToken)
+	if err != nil {
+		return usecase.ProviderProfile{}, err
+	}
+	return usecase.ProviderProfile{
+		Subject: userinfo.Sub,
+		Name:    userinfo.Name,
+		Picture: userinfo.Picture,
+		Email:   userinfo.Email,
+	}, nil
+}
+
+func (provider *oidcProvider) VerifyIDToken(idToken string, nonce string) (usecase.ProviderProfile, error) {
+	if _, err := provider.configuration(); err != nil {
+		return usecase.ProviderProfile{}, err
+	}
+	claims, err := provider.idToken.Verify(idToken, nonce)
+	if err != nil {
+		return usecase.ProviderProfile{}, err
+	}
+	return profileFromIDToken(claims), nil
+}
+
+// RFC 7009のトークン無効化。エンドポイントがないプロバイダーでは何もしない
+func (provider *oidcProvider) Revoke(accessToken string) error {
+	config, err := provider.configuration()
+	if err != nil {
+		return err
+	}
+	if config.RevocationEndpoint == "" {
+		return nil
+	}
+	form := url.Values{}
+	form.Set("token", accessToken)
+	form.Set("token_type_hint", "access_token")
+	req, err := http.NewRequest(http.MethodPost, config.RevocationEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return domain.InternalError(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(provider.clientID, provider.clientSecret)
+	return doProviderRequest(provider.client, req, nil)
+}
@@ -0,0 +1,103 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+// IDプロバイダーへのリクエストのタイムアウト
+const identityProviderTimeout = 10 * time.Second
+
+// IDENTITY_PROVIDERにカンマ区切りで書いたIDプロバイダーでログインできるようにする(lineとoidc。デフォルトはline)
+// 最初に書いたものが、providerを指定しなかったときに使うプロバイダーになる
+func newIdentityProviders() usecase.IdentityProviders {
+	client := &http.Client{Timeout: identityProviderTimeout}
+	providers := usecase.IdentityProviders{}
+	for _, name := range identityProviderNames() {
+		switch name {
+		case "line":
+			providers = append(providers, newLINEProvider(client))
+		case "oidc":
+			providers = append(providers, newOIDCProvider(client))
+		default:
+			panic("unknown IDENTITY_PROVIDER: " + name)
+		}
+	}
+	return providers
+}
+
+func identityProviderNames() []string {
+	var names []string
+	for _, name := range strings.Split(os.Getenv("IDENTITY_PROVIDER"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return []string{"line"}
+	}
+	return names
+}
+
+// ADMIN_SUBJECTSにカンマ区切りで書いた"プロバイダー:ユーザーID"のユーザーは、ログインすると管理者になる
+// プロバイダーのないものは、どのプロバイダーのユーザーか分からないので使わない
+func adminSubjects() []domain.Identity {
+	var subjects []domain.Identity
+	for _, entry := range strings.Split(os.Getenv("ADMIN_SUBJECTS"), ",") {
+		i := strings.Index(entry, ":")
+		if i < 0 {
+			continue
+		}
+		provider, subject := strings.TrimSpace(entry[:i]), strings.TrimSpace(entry[i+1:])
+		if provider != "" && subject != "" {
+			subjects = append(subjects, domain.Identity{Provider: provider, Subject: subject})
+		}
+	}
+	return subjects
+}
+
+// 末尾の/を除いたURL。空ならdefaultURL
+func baseURL(name string, defaultURL string) string {
+	url := os.Getenv(name)
+	if url == "" {
+		url = defaultURL
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// リクエストを送り、200ならレスポンスをoutに読み込む
+// 400、401、403はアクセストークンが無効なのでdomain.ErrUnauthorized、それ以外の失敗は内部エラーにする
+func doProviderRequest(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return domain.InternalError(err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return domain.InternalError(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		return domain.UnauthorizedError("invalid access token")
+	default:
+		return domain.InternalError(fmt.Errorf("%s %s: status %d: %s", req.Method, req.URL.Path, resp.StatusCode, b))
+	}
+	if out == nil || len(b) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return domain.InternalError(err)
+	}
+	return nil
+}
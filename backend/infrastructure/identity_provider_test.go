@@ -0,0 +1,204 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/fakeidp"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+const fakeClientID = "1234"
+
+// cmd/fakeidpと同じ偽のIDプロバイダーをhttptestで動かす
+// issuerにはサーバーのURLを使うので、起動してからハンドラーを作る
+func startFakeIdP(t *testing.T) *httptest.Server {
+	var handler http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	idp, err := fakeidp.New(server.URL, fakeClientID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler = idp
+	return server
+}
+
+// 偽のIDプロバイダーにsubのユーザーのアクセストークンとIDトークンを発行させる
+func issueFakeToken(t *testing.T, server *httptest.Server, sub string, nonce string) (string, string) {
+	return issueFakeTokenFor(t, server, fakeClientID, sub, nonce)
+}
+
+// clientIDのクライアントに発行させる
+func issueFakeTokenFor(t *testing.T, server *httptest.Server, clientID string, sub string, nonce string) (string, string) {
+	res, err := server.Client().PostForm(server.URL+"/token", url.Values{
+		"client_id":      {clientID},
+		"sub":            {sub},
+		"name":           {"Alice"},
+		"email":          {"alice@example.com"},
+		"status_message": {"積読中"},
+		"nonce":          {nonce},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	tokens := struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&tokens); err != nil {
+		t.Fatal(err)
+	}
+	return tokens.AccessToken, tokens.IDToken
+}
+
+// テストの間だけ環境変数を変える
+func setenv(t *testing.T, name string, value string) {
+	old, ok := os.LookupEnv(name)
+	os.Setenv(name, value)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(name, old)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
+
+func TestLINEProvider(t *testing.T) {
+	server := startFakeIdP(t)
+	setenv(t, "LINE_API_BASE_URL", server.URL)
+	setenv(t, "LINE_ID_TOKEN_ISSUER", server.URL)
+	setenv(t, "CHANNEL_ID", fakeClientID)
+	setenv(t, "CHANNEL_SECRET", "")
+	provider := newLINEProvider(server.Client())
+
+	testIdentityProvider(t, server, provider, usecase.ProviderProfile{
+		Subject: "U1234", Name: "Alice", StatusMessage: "積読中",
+	})
+
+	t.Run("access token for another channel", func(t *testing.T) {
+		accessToken, _ := issueFakeToken(t, server, "U1234", "nonce")
+		other := newLINEProvider(server.Client())
+		other.channelID = "5678"
+		if err := other.VerifyAccessToken(accessToken); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("got error %v, want %v", err, domain.ErrUnauthorized)
+		}
+	})
+}
+
+func TestOIDCProvider(t *testing.T) {
+	server := startFakeIdP(t)
+	setenv(t, "OIDC_ISSUER", server.URL)
+	setenv(t, "OIDC_CLIENT_ID", fakeClientID)
+	setenv(t, "OIDC_CLIENT_SECRET", "")
+	provider := newOIDCProvider(server.Client())
+
+	testIdentityProvider(t, server, provider, usecase.ProviderProfile{
+		Subject: "U1234", Name: "Alice", Email: "alice@example.com",
+	})
+
+	t.Run("access token for another client", func(t *testing.T) {
+		accessToken, _ := issueFakeTokenFor(t, server, "5678", "U1234", "nonce")
+		if err := provider.VerifyAccessToken(accessToken); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("got error %v, want %v", err, domain.ErrUnauthorized)
+		}
+	})
+
+	t.Run("no introspection endpoint", func(t *testing.T) {
+		other := newOIDCProvider(server.Client())
+		config, err := other.configuration()
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.IntrospectionEndpoint = ""
+		accessToken, _ := issueFakeToken(t, server, "U1234", "nonce")
+		if err := other.VerifyAccessToken(accessToken); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("got error %v, want %v", err, domain.ErrUnauthorized)
+		}
+	})
+}
+
+// LINEとOpenID Connectで共通の確認。profileはアクセストークンで取得できるプロフィール
+func testIdentityProvider(t *testing.T, server *httptest.Server, provider usecase.IdentityProvider, profile usecase.ProviderProfile) {
+	t.Run("verify access token", func(t *testing.T) {
+		accessToken, _ := issueFakeToken(t, server, profile.Subject, "nonce")
+		if err := provider.VerifyAccessToken(accessToken); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := provider.VerifyAccessToken("unknown"); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("got error %v, want %v", err, domain.ErrUnauthorized)
+		}
+	})
+
+	t.Run("profile", func(t *testing.T) {
+		accessToken, _ := issueFakeToken(t, server, profile.Subject, "nonce")
+		got, err := provider.Profile(accessToken)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != profile {
+			t.Errorf("got %+v, want %+v", got, profile)
+		}
+		if _, err := provider.Profile("unknown"); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("got error %v, want %v", err, domain.ErrUnauthorized)
+		}
+	})
+
+	t.Run("verify id token", func(t *testing.T) {
+		_, idToken := issueFakeToken(t, server, profile.Subject, "nonce")
+		got, err := provider.VerifyIDToken(idToken, "nonce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := usecase.ProviderProfile{Subject: profile.Subject, Name: "Alice", Email: "alice@example.com"}
+		if got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+		for _, tt := range []struct {
+			name    string
+			idToken string
+			nonce   string
+		}{
+			{"other nonce", idToken, "other"},
+			{"no nonce", idToken, ""},
+			{"malformed", "not.a.jwt", "nonce"},
+		} {
+			if _, err := provider.VerifyIDToken(tt.idToken, tt.nonce); !errors.Is(err, domain.ErrUnauthorized) {
+				t.Errorf("%s: got error %v, want %v", tt.name, err, domain.ErrUnauthorized)
+			}
+		}
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		accessToken, _ := issueFakeToken(t, server, profile.Subject, "nonce")
+		if err := provider.Revoke(accessToken); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := provider.VerifyAccessToken(accessToken); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("revoked token: got error %v, want %v", err, domain.ErrUnauthorized)
+		}
+	})
+}
+
+func TestAdminSubjects(t *testing.T) {
+	setenv(t, "ADMIN_SUBJECTS", " line:U1234 , oidc:alice@example.com:1,U5678,:U9,line:")
+	got := adminSubjects()
+	want := []domain.Identity{
+		{Provider: "line", Subject: "U1234"},
+		{Provider: "oidc", Subject: "alice@example.com:1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package infrastructure
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/controllers"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+// ゴミ箱に入れてから完全に削除するまでの日数のデフォルト
+const defaultTrashRetentionDays = 30
+
+// 退会を予約してから削除するまでの日数のデフォルト
+const defaultAccountDeletionGraceDays = 14
+
+// 保持期間を過ぎたゴミ箱の中身を1時間ごとに削除する
+func startTrashPurger(trashController *controllers.TrashController, logger echo.Logger) {
+	retention := trashRetention()
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if err := trashController.PurgeExpired(retention); err != nil {
+				logger.Errorf("ゴミ箱の削除に失敗しました: %v", err)
+			}
+		}
+	}()
+}
+
+// TRASH_RETENTION_DAYSで保持期間を変えられる
+func trashRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = defaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// 期限切れのセッションを1時間ごとに削除する
+func startSessionPurger(sessionController *controllers.SessionController, logger echo.Logger) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if err := sessionController.PurgeExpired(); err != nil {
+				logger.Errorf("期限切れのセッションの削除に失敗しました: %v", err)
+			}
+		}
+	}()
+}
+
+// 猶予期間が過ぎた退会済みのユーザーを1時間ごとに削除する
+func startAccountPurger(accountController *controllers.AccountController, logger echo.Logger) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if err := accountController.PurgeScheduled(); err != nil {
+				logger.Errorf("退会したユーザーの削除に失敗しました: %v", err)
+			}
+		}
+	}()
+}
+
+// ACCOUNT_DELETION_GRACE_DAYSで猶予期間を変えられる
+func accountDeletionGracePeriod() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_GRACE_DAYS"))
+	if err != nil || days <= 0 {
+		days = defaultAccountDeletionGraceDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// 追加されたサイトの積読の読む時間を1分ごとに見積もる
+func startReadingTimeEstimator(readingTimeController *controllers.ReadingTimeController, logger echo.Logger) {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if err := readingTimeController.EstimatePending(); err != nil {
+				logger.Errorf("読む時間の見積もりに失敗しました: %v", err)
+			}
+		}
+	}()
+}
+
+// READING_SPEED_JA(1分間に読む文字数)とREADING_SPEED_EN(1分間に読む単語数)で見積もりに使う速さを変えられる
+func readingSpeeds() usecase.ReadingSpeeds {
+	chars, _ := strconv.Atoi(os.Getenv("READING_SPEED_JA"))
+	words, _ := strconv.Atoi(os.Getenv("READING_SPEED_EN"))
+	// 0以下ならデフォルトの速さになる
+	return usecase.ReadingSpeeds{CharsPerMinute: chars, WordsPerMinute: words}
+}
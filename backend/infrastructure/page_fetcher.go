@@ -0,0 +1,182 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// ページの取得のタイムアウト
+	pageFetchTimeout = 15 * time.Second
+	// 読み込むページの大きさの上限
+	maxPageBytes = 5 << 20
+)
+
+// 本文ではない要素
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "svg": true, "iframe": true,
+	"head": true, "nav": true, "header": true, "footer": true, "aside": true, "form": true, "button": true,
+}
+
+// 接続しないプライベートアドレスの範囲
+var privateBlocks = func() []*net.IPNet {
+	var blocks []*net.IPNet
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7"} {
+		_, block, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err.Error())
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}()
+
+// ユーザーが登録したURLのページを取得する
+// サーバーの内部のネットワークを覗かれないように、プライベートアドレスには接続しない
+type httpPageFetcher struct {
+	client *http.Client
+}
+
+func newPageFetcher() *httpPageFetcher {
+	return newPageFetcherWithDialer(&net.Dialer{
+		Timeout: pageFetchTimeout,
+		Control: rejectPrivateAddress,
+	})
+}
+
+// 手元のサーバーのページを取得して試すときは、Controlのないdialerを渡す
+func newPageFetcherWithDialer(dialer *net.Dialer) *httpPageFetcher {
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: pageFetchTimeout,
+	}
+	return &httpPageFetcher{
+		client: &http.Client{Timeout: pageFetchTimeout, Transport: transport},
+	}
+}
+
+// 名前解決したあとのアドレスを調べるので、リダイレクトやDNSで内部を指されても防げる
+func rejectPrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("refusing to connect to %s", address)
+	}
+	for _, block := range privateBlocks {
+		if block.Contains(ip) {
+			return fmt.Errorf("refusing to connect to %s", address)
+		}
+	}
+	return nil
+}
+
+func (fetcher *httpPageFetcher) FetchText(pageURL string) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", domain.ValidationError("url must be http or https")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pageFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", domain.ValidationError("invalid url")
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain")
+	req.Header.Set("User-Agent", "TSUNTSUN reading-time estimator")
+
+	resp, err := fetcher.client.Do(req)
+	if err != nil {
+		return "", domain.InternalError(err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		// 時間をおけば取得できるかもしれない
+		return "", domain.InternalError(fmt.Errorf("GET %s: status %d", pageURL, resp.StatusCode))
+	default:
+		return "", domain.NotFoundError(fmt.Sprintf("GET %s: status %d", pageURL, resp.StatusCode))
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" && mediaType != "text/plain" {
+		return "", domain.ValidationError("unsupported content type: " + mediaType)
+	}
+	// Shift_JISなどのページもUTF-8にして読む
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxPageBytes), contentType)
+	if err != nil {
+		return "", domain.ValidationError("unsupported charset: " + err.Error())
+	}
+	if mediaType == "text/plain" {
+		b, err := io.ReadAll(body)
+		if err != nil {
+			return "", domain.InternalError(err)
+		}
+		return string(b), nil
+	}
+
+	doc, err := html.Parse(body)
+	if err != nil {
+		return "", domain.InternalError(err)
+	}
+	return mainText(doc), nil
+}
+
+// articleかmainがあればその中、なければbodyの文章を取り出す
+func mainText(doc *html.Node) string {
+	root := findElement(doc, "article")
+	if root == nil {
+		root = findElement(doc, "main")
+	}
+	if root == nil {
+		root = doc
+	}
+	var b strings.Builder
+	collectText(root, &b)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func findElement(node *html.Node, name string) *html.Node {
+	if node.Type == html.ElementNode && node.Data == name {
+		return node
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElement(child, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func collectText(node *html.Node, b *strings.Builder) {
+	switch node.Type {
+	case html.TextNode:
+		b.WriteString(node.Data)
+		b.WriteByte(' ')
+		return
+	case html.ElementNode:
+		if skippedElements[node.Data] {
+			return
+		}
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		collectText(child, b)
+	}
+}
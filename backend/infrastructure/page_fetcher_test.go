@@ -0,0 +1,115 @@
+package infrastructure
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestFetchText(t *testing.T) {
+	sjis, err := japanese.ShiftJIS.NewEncoder().String(`<html><head><title>題名</title></head><body>
+		<nav>メニュー</nav><article><p>積読を読む時間を見積もる。</p></article><footer>フッター</footer></body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	english := `<html><body><header>Site</header><main><h1>Reading</h1><p>Don't pile up well-known books.</p>
+		<script>var skipped = 1;</script></main></body></html>`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sjis", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=Shift_JIS")
+		w.Write([]byte(sjis))
+	})
+	mux.HandleFunc("/english", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(english))
+	})
+	mux.HandleFunc("/pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4"))
+	})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusInternalServerError)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// 手元のサーバーに接続するので、プライベートアドレスを拒否しないdialerを使う
+	fetcher := newPageFetcherWithDialer(&net.Dialer{Timeout: pageFetchTimeout})
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{"shift_jis page", "/sjis", "積読を読む時間を見積もる。", nil},
+		{"english page", "/english", "Reading Don't pile up well-known books.", nil},
+		{"pdf is not retried", "/pdf", "", domain.ErrValidation},
+		{"404 is not retried", "/missing", "", domain.ErrNotFound},
+		{"500 is retried", "/error", "", domain.ErrInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := fetcher.FetchText(server.URL + tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if text != tt.want {
+				t.Errorf("got %q, want %q", text, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchTextRefusesPrivateAddress(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	_, err := newPageFetcher().FetchText(server.URL)
+	if err == nil || !strings.Contains(err.Error(), "refusing to connect") {
+		t.Fatalf("got error %v, want refusing to connect", err)
+	}
+	if called {
+		t.Error("request reached the loopback server")
+	}
+}
+
+func TestRejectPrivateAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		refused bool
+	}{
+		{"127.0.0.1:80", true},
+		{"[::1]:80", true},
+		{"10.1.2.3:443", true},
+		{"172.16.0.1:443", true},
+		{"192.168.1.1:80", true},
+		{"169.254.169.254:80", true},
+		{"100.64.0.1:80", true},
+		{"[fd00::1]:443", true},
+		{"0.0.0.0:80", true},
+		{"93.184.216.34:443", false},
+		{"[2606:2800:220:1::1]:443", false},
+	}
+	for _, tt := range tests {
+		err := rejectPrivateAddress("tcp", tt.address, nil)
+		if refused := err != nil; refused != tt.refused {
+			t.Errorf("%s: refused = %v, want %v", tt.address, refused, tt.refused)
+		}
+	}
+}
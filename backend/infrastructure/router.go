@@ -1,30 +1,56 @@
 package infrastructure
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
 	"github.com/yot-sailing/TSUNTSUN/body"
+	"github.com/yot-sailing/TSUNTSUN/domain"
 	"github.com/yot-sailing/TSUNTSUN/interfaces/controllers"
 	authMiddleware "github.com/yot-sailing/TSUNTSUN/middleware"
 )
 
+// 退会で削除する日時を返すヘッダー
+const deletionScheduledAtHeader = "X-Deletion-Scheduled-At"
+
 func Init() {
 	e := echo.New()
+	e.HTTPErrorHandler = errorHandler
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete},
+		AllowMethods: []string{http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
+		// ブラウザから退会したときにファイル名と削除する日時を読めるようにする
+		ExposeHeaders: []string{echo.HeaderContentDisposition, deletionScheduledAtHeader},
 	}))
 	userController := controllers.NewUserController(NewSqlHandler())
 	tsundokuController := controllers.NewTsundokuController(NewSqlHandler())
 	tagController := controllers.NewTagController(NewSqlHandler())
-	tsundokuTagController := controllers.NewTsundokuTagController(NewSqlHandler())
+	trashController := controllers.NewTrashController(NewSqlHandler())
+	personalAccessTokenController := controllers.NewPersonalAccessTokenController(NewSqlHandler())
+	identityProviders := newIdentityProviders()
+	loginController := controllers.NewLoginController(NewSqlHandler(), identityProviders, adminSubjects())
+	identityController := controllers.NewIdentityController(NewSqlHandler(), identityProviders)
+	readingSessionController := controllers.NewReadingSessionController(NewSqlHandler(), readingSpeeds())
+	progressController := controllers.NewProgressController(NewSqlHandler())
+	recommendationController := controllers.NewRecommendationController(NewSqlHandler())
+	readingTimeController := controllers.NewReadingTimeController(NewSqlHandler(), newPageFetcher(), readingSpeeds())
+	accountController := controllers.NewAccountController(NewSqlHandler(), identityProviders, accountDeletionGracePeriod())
+	sessionController := controllers.NewSessionController(NewSqlHandler(), newJWTAccessTokens(e.Logger), sessionTTL("ACCESS_TOKEN_TTL"), sessionTTL("REFRESH_TOKEN_TTL"))
+
+	// 保持期間を過ぎたゴミ箱の中身を定期的に削除
+	startTrashPurger(trashController, e.Logger)
+	// 期限切れのセッションを定期的に削除
+	startSessionPurger(sessionController, e.Logger)
+	// サイトの積読の読む時間をページの本文から見積もる
+	startReadingTimeEstimator(readingTimeController, e.Logger)
+	// 猶予期間が過ぎた退会済みのユーザーを定期的に削除
+	startAccountPurger(accountController, e.Logger)
 
 	// Middleware
 	logger := middleware.LoggerWithConfig(middleware.LoggerConfig{
@@ -39,10 +65,17 @@ func Init() {
 		return c.String(http.StatusOK, "This is test!")
 	})
 
-	// LINE
 	// ログイン
+	// IDプロバイダー(LINEなど)のIDトークンかアクセストークンを確認して、以降のAPIで使うトークンを発行する
+	// IDトークンはフォームのid_tokenとnonce、アクセストークンはAuthorizationヘッダーで受け取る
+	// 複数のIDプロバイダーを使えるときは、フォームのproviderで選ぶ
 	e.POST("/api/line_login", func(c echo.Context) error {
-		user, err := authMiddleware.AuthUser(c.Request().Header.Get("Authorization"), userController)
+		user, err := loginController.Login(c)
+		if err != nil {
+			return err
+		}
+
+		sessionToken, err := sessionController.Start(user.ID)
 		if err != nil {
 			return err
 		}
@@ -50,211 +83,628 @@ func Init() {
 		userExcludeLine := body.UesrExcludeLine{
 			ID:        user.ID,
 			Name:      user.Name,
+			Role:      user.Role,
 			CreatedAt: user.CreatedAt,
 			UpdatedAt: user.UpdatedAt,
 		}
 
-		return c.JSON(http.StatusOK, userExcludeLine)
+		return c.JSON(http.StatusOK, body.LoginResponse{UesrExcludeLine: userExcludeLine, SessionToken: sessionToken})
 	})
 
-	// ログアウト
-	e.POST("/api/line_logout", func(c echo.Context) error {
-		accessToken := c.Request().Header.Get("Authorization")
+	// リフレッシュトークンでトークンを発行し直す
+	e.POST("/api/token/refresh", func(c echo.Context) error {
+		sessionToken, err := sessionController.Refresh(c)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, sessionToken)
+	})
 
-		revokeRequestBody := &body.RevokeRequestBody{
-			ClientID:      os.Getenv("CHANNEL_ID"),
-			ClientSercret: os.Getenv("CHANNEL_SECRET"),
-			AccessToken:   accessToken[7:],
+	// セッションを終わらせる
+	e.POST("/api/logout", func(c echo.Context) error {
+		if err := sessionController.End(c); err != nil {
+			return err
 		}
+		return c.NoContent(http.StatusNoContent)
+	})
 
-		revokeJsonString, err := json.Marshal(revokeRequestBody)
+	// IDプロバイダーのアクセストークンを無効にする
+	e.POST("/api/line_logout", func(c echo.Context) error {
+		if err := loginController.Logout(c); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "logout")
+	})
+
+	// ここから下のAPIはアクセストークンが必要
+	api := e.Group("/api", authMiddleware.Authenticate(sessionController, personalAccessTokenController, trustedProxies()))
+
+	// ユーザー管理は管理者だけができる。個人用アクセストークンではadminスコープも必要
+	// ユーザー全取得
+	api.GET("/users", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		users, err := userController.GetUser(user.ID)
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
+		return c.JSON(http.StatusOK, users)
+	}, authMiddleware.RequireScope(domain.ScopeAdmin))
 
-		endpoint := "https://api.line.me/oauth2/v2.1/revoke"
-		req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(revokeJsonString))
+	// ユーザー作成
+	api.POST("/users", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		created, err := userController.Create(c, user.ID)
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return c.JSON(http.StatusCreated, created)
+	}, authMiddleware.RequireScope(domain.ScopeAdmin))
 
-		client := new(http.Client)
-		resp, err := client.Do(req)
+	// ユーザーの役割を変える
+	api.PUT("/users/:userID/role", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		userID, err := strconv.Atoi(c.Param("userID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid userID")
+		}
+		changed, err := userController.ChangeRole(c, user.ID, userID)
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
-		defer resp.Body.Close()
+		return c.JSON(http.StatusOK, changed)
+	}, authMiddleware.RequireScope(domain.ScopeAdmin))
 
-		// // 成功していたら空
-		// byteArray, err := ioutil.ReadAll(resp.Body)
-		// if err != nil {
-		// 	fmt.Println(err)
-		// }
+	// ユーザー削除。猶予期間なしですぐに消す。本人の退会はDELETE /api/users
+	api.DELETE("/users/:userID", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		userID, err := strconv.Atoi(c.Param("userID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid userID")
+		}
+		if err := userController.Delete(user.ID, userID); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "deleted")
+	}, authMiddleware.RequireScope(domain.ScopeAdmin))
 
-		return c.String(resp.StatusCode, "logout")
+	// ログインしているユーザーのプロフィール
+	api.GET("/me", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		me, err := userController.GetMe(user.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, me)
 	})
 
-	// ユーザー全取得
-	e.GET("/api/users", func(c echo.Context) error {
-		users := userController.GetUser()
-		c.Bind(&users)
-		return c.JSON(http.StatusOK, users)
+	// プロフィールの更新。名前とアイコンを変えると、ログインしてもLINEのものに戻さない
+	api.PATCH("/me", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		me, err := userController.UpdateMe(c, user.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, me)
 	})
 
-	// ユーザー作成
-	e.POST("/api/users", func(c echo.Context) error {
-		userController.Create(c)
-		return c.String(http.StatusOK, "created")
+	// 読書の記録から学んだ読む速さと、見積もった時間と実際に読んだ時間の比較
+	api.GET("/me/reading_speed", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		report, err := readingSessionController.Report(user.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, report)
 	})
 
-	// ユーザー削除
-	e.DELETE("/api/users", func(c echo.Context) error {
-		user, err := authMiddleware.AuthUser(c.Request().Header.Get("Authorization"), userController)
+	// すべてのデータをzipで書き出す
+	api.GET("/me/export", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		archive, err := accountController.Export(user.ID)
 		if err != nil {
 			return err
 		}
-		userController.Delete(user.ID)
-		return c.String(http.StatusOK, "deleted")
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="tsuntsun-export.zip"`)
+		return c.Blob(http.StatusOK, "application/zip", archive)
+	}, authMiddleware.RequireScope(domain.ScopeAdmin))
+
+	// 退会。すぐには削除せず、猶予期間のうちにログインすれば取り消せる
+	// 個人用アクセストークンは取り消すまで使えず、取り消せばそのまま使える
+	// 先に書き出したデータのzipを返し、削除する日時はX-Deletion-Scheduled-Atヘッダーで返す
+	api.DELETE("/users", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		scheduledAt, archive, err := accountController.ScheduleDeletion(c, user.ID)
+		if err != nil {
+			return err
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="tsuntsun-export.zip"`)
+		c.Response().Header().Set(deletionScheduledAtHeader, scheduledAt.Format(time.RFC3339))
+		return c.Blob(http.StatusAccepted, "application/zip", archive)
+	}, authMiddleware.RequireScope(domain.ScopeAdmin))
+
+	// 個人用アクセストークン
+	// 一覧
+	api.GET("/tokens", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		tokens, err := personalAccessTokenController.GetTokens(user.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, tokens)
+	}, authMiddleware.RequireScope(domain.ScopeAdmin))
+
+	// 発行
+	api.POST("/tokens", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		token, err := personalAccessTokenController.CreateToken(c, user.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, token)
+	}, authMiddleware.RequireScope(domain.ScopeAdmin))
+
+	// 無効にする
+	api.DELETE("/tokens/:tokenID", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		tokenID, err := strconv.Atoi(c.Param("tokenID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tokenID")
+		}
+		if err := personalAccessTokenController.Delete(user.ID, tokenID); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	}, authMiddleware.RequireScope(domain.ScopeAdmin))
+
+	// ログインに使うIDプロバイダーのアカウント
+	api.GET("/identities", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		identities, err := identityController.GetIdentities(user.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, identities)
 	})
 
-	// 積読全取得
-	e.GET("api/tsundokus", func(c echo.Context) error {
-		user, err := authMiddleware.AuthUser(c.Request().Header.Get("Authorization"), userController)
+	// 別のIDプロバイダーのアカウントを紐づける。どれでログインしても同じユーザーになる
+	api.POST("/identities", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		identity, err := identityController.Link(c, user.ID)
 		if err != nil {
 			return err
 		}
+		return c.JSON(http.StatusCreated, identity)
+	}, authMiddleware.RequireScope(domain.ScopeAdmin))
 
-		tsundokus := tsundokuController.GetTsundoku(user.ID)
-		c.Bind(&tsundokus)
-		for i, tsundoku := range tsundokus {
-			tsundokuTags := tsundokuTagController.GetTsundokuTagsByTsundokuIDandUserID(tsundoku.ID, user.ID)
-			var tagIDs []int
-			for _, tsundokuTag := range tsundokuTags {
-				tagIDs = append(tagIDs, tsundokuTag.TagID)
-			}
-			tsundokus[i].Tags = tagController.GetTags(tagIDs)
+	// 紐づけを外す。最後の一つは外せない
+	api.DELETE("/identities/:identityID", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		identityID, err := strconv.Atoi(c.Param("identityID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid identityID")
+		}
+		if err := identityController.Unlink(user.ID, identityID); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	}, authMiddleware.RequireScope(domain.ScopeAdmin))
+
+	// 積読全取得
+	api.GET("/tsundokus", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		// ?status=unread,reading&tags=Go のように絞り込める
+		// ?sort=deadline&limit=50 のようにページに分けられる
+		tsundokus, nextCursor, err := tsundokuController.GetTsundokuByFilter(c, user.ID)
+		if err != nil {
+			return err
+		}
+		if controllers.IsPaged(c) {
+			return c.JSON(http.StatusOK, body.NewPageResponse(tsundokus, nextCursor))
 		}
 		return c.JSON(http.StatusOK, tsundokus)
 	})
 
+	// 積読検索
+	api.GET("/search", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		results, err := tsundokuController.Search(c, user.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, results)
+	})
+
 	// 積読追加
-	e.POST("api/tsundokus", func(c echo.Context) error {
-		user, err := authMiddleware.AuthUser(c.Request().Header.Get("Authorization"), userController)
+	api.POST("/tsundokus", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		tsundoku, err := tsundokuController.CreateTsundoku(c, user.ID)
 		if err != nil {
 			return err
 		}
-		tsundokuController.CreateTsundoku(c, user.ID)
-		return c.String(http.StatusOK, "created tsundoku")
+		tsundoku.Tags = []domain.Tag{}
+		return c.JSON(http.StatusCreated, tsundoku)
 	})
 
-	// 積読削除
-	// TODO:ユーザーが管理しているかの判定
-	e.DELETE("api/tsundokus/:tsundokuID", func(c echo.Context) error {
-		str_tsundokuID := c.Param("tsundokuID")
-		tsundokuID, err := strconv.Atoi(str_tsundokuID)
+	// 積読更新
+	updateTsundoku := func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tsundokuID, err := strconv.Atoi(c.Param("tsundokuID"))
 		if err != nil {
-			fmt.Println(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
+		}
+
+		tsundoku, err := tsundokuController.UpdateTsundoku(c, user.ID, tsundokuID)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, tsundoku)
+	}
+	api.PUT("/tsundokus/:tsundokuID", updateTsundoku)
+	api.PATCH("/tsundokus/:tsundokuID", updateTsundoku)
+
+	// 読書状態の変更
+	changeStatus := func(status string) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := authMiddleware.CurrentUser(c)
+
+			tsundokuID, err := strconv.Atoi(c.Param("tsundokuID"))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
+			}
+
+			tsundoku, err := tsundokuController.ChangeStatus(user.ID, tsundokuID, status)
+			if err != nil {
+				return err
+			}
+
+			return c.JSON(http.StatusOK, tsundoku)
+		}
+	}
+	// 読み始める(投げ出したものを再開するときも)
+	api.POST("/tsundokus/:tsundokuID/start", changeStatus(domain.StatusReading))
+	// 読み終わる
+	api.POST("/tsundokus/:tsundokuID/finish", changeStatus(domain.StatusDone))
+	// 投げ出す
+	api.POST("/tsundokus/:tsundokuID/abandon", changeStatus(domain.StatusAbandoned))
+
+	// 本をどこまで読んだかの記録と読み進め具合
+	api.GET("/tsundokus/:tsundokuID/progress", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tsundokuID, err := strconv.Atoi(c.Param("tsundokuID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
+		}
+		progress, err := progressController.Get(user.ID, tsundokuID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, progress)
+	})
+
+	// {"page": 120, "minutes": 25} でどのページまで読んだか記録する。最後のページなら読み終わり
+	api.POST("/tsundokus/:tsundokuID/progress", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tsundokuID, err := strconv.Atoi(c.Param("tsundokuID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
+		}
+		progress, err := progressController.Record(c, user.ID, tsundokuID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, progress)
+	})
+
+	// 積読を読んだ時間の記録
+	api.GET("/tsundokus/:tsundokuID/sessions", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tsundokuID, err := strconv.Atoi(c.Param("tsundokuID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
+		}
+		sessions, err := readingSessionController.GetReadingSessions(user.ID, tsundokuID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, sessions)
+	})
+
+	// 読書のタイマーを動かす。一人一つまで
+	api.POST("/tsundokus/:tsundokuID/sessions", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tsundokuID, err := strconv.Atoi(c.Param("tsundokuID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
+		}
+		session, err := readingSessionController.Start(user.ID, tsundokuID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, session)
+	})
+
+	// 読書のタイマーを止めて読んだ時間を記録する。{"page": 120}で本の読み進めたページ、{"finished": true}で読み終わり
+	api.POST("/tsundokus/:tsundokuID/sessions/stop", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tsundokuID, err := strconv.Atoi(c.Param("tsundokuID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
+		}
+		session, err := readingSessionController.Stop(c, user.ID, tsundokuID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, session)
+	})
+
+	// 積読削除(ゴミ箱に入れる)
+	api.DELETE("/tsundokus/:tsundokuID", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tsundokuID, err := strconv.Atoi(c.Param("tsundokuID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
+		}
+		if err := tsundokuController.Delete(user.ID, tsundokuID); err != nil {
+			return err
 		}
-		tsundokuController.Delete(tsundokuID)
 		return c.String(http.StatusOK, "deleted tsundoku")
 	})
 
 	// ある時間以内に読める本を取得
-	e.GET("api/time/:time", func(c echo.Context) error {
-		user, err := authMiddleware.AuthUser(c.Request().Header.Get("Authorization"), userController)
+	api.GET("/time/:time", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		total_min, err := strconv.Atoi(c.Param("time"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid time")
+		}
+		tsundokus, err := tsundokuController.GetFreeTsundoku(c, user.ID, total_min)
 		if err != nil {
 			return err
 		}
-		total_min, _ := strconv.Atoi(c.Param("time"))
-		tsundokus := tsundokuController.GetFreeTsundoku(c, user.ID, total_min)
-		c.Bind(&tsundokus)
 		return c.JSON(http.StatusOK, tsundokus)
 	})
 
-	// ユーザーが管理するタグ全取得
-	e.GET("api/tags", func(c echo.Context) error {
-		user, err := authMiddleware.AuthUser(c.Request().Header.Get("Authorization"), userController)
+	// 空き時間に読む積読の計画。時間内に収まる組み合わせのうち、締め切り、積んでからの日数、優先度で一番価値があるもの
+	// ページ数の分かる本は「20ページ読む」のように読む速さに合わせて少しずつ読む
+	api.GET("/plan", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		plan, err := tsundokuController.Plan(c, user.ID)
 		if err != nil {
 			return err
 		}
+		return c.JSON(http.StatusOK, plan)
+	})
 
-		// TsundokuTagテーブルのユーザーの管理下のものを取得
-		tsundokuTags := tsundokuTagController.GetTsundokuTags(user.ID)
-		var tagIDs []int
-		for _, tsundokuTag := range tsundokuTags {
-			tagIDs = append(tagIDs, tsundokuTag.TagID)
+	// 今日のおすすめ。締め切り、積んでからの日数、読む時間、最近読んだ積読のタグで点数をつけ、日替わりで入れ替える
+	api.GET("/recommendations", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		recommendations, err := recommendationController.Recommend(c, user.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, recommendations)
+	})
+
+	// ユーザーが管理するタグ全取得
+	api.GET("/tags", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tags, nextCursor, err := tagController.GetUserTags(c, user.ID)
+		if err != nil {
+			return err
+		}
+		if controllers.IsPaged(c) {
+			return c.JSON(http.StatusOK, body.NewPageResponse(tags, nextCursor))
 		}
-		// tagIDからtagを取得
-		tags := tagController.GetTags(tagIDs)
-		// c.Bind(&tags)
 		return c.JSON(http.StatusOK, tags)
 	})
 
-	// ユーザーが管理する積読についているタグ全取得
-	e.GET("api/tsundokus/:tsundokuID/tags", func(c echo.Context) error {
-		user, err := authMiddleware.AuthUser(c.Request().Header.Get("Authorization"), userController)
+	// タグ名の変更
+	api.PATCH("/tags/:tagID", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tagID, err := strconv.Atoi(c.Param("tagID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tagID")
+		}
+		tag, err := tagController.Rename(c, user.ID, tagID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, tag)
+	})
+
+	// タグ削除(すべての積読から外してゴミ箱に入れる)
+	api.DELETE("/tags/:tagID", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tagID, err := strconv.Atoi(c.Param("tagID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tagID")
+		}
+		if err := tagController.DeleteTag(user.ID, tagID); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "deleted tag")
+	})
+
+	// タグの統合
+	api.POST("/tags/merge", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tag, err := tagController.Merge(c, user.ID)
 		if err != nil {
 			return err
 		}
+		return c.JSON(http.StatusOK, tag)
+	})
+
+	// ユーザーが管理する積読についているタグ全取得
+	api.GET("/tsundokus/:tsundokuID/tags", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
 
 		str_tsundokuID := c.Param("tsundokuID")
 		// intに変換
 		tsundokuID, err := strconv.Atoi(str_tsundokuID)
 		if err != nil {
-			return err
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
 		}
 
-		tsundokuTags := tsundokuTagController.GetTsundokuTagsByTsundokuIDandUserID(tsundokuID, user.ID)
-		var tagIDs []int
-		for _, tsundokuTag := range tsundokuTags {
-			tagIDs = append(tagIDs, tsundokuTag.TagID)
+		tags, err := tsundokuController.GetTags(user.ID, tsundokuID)
+		if err != nil {
+			return err
 		}
-		// tagIDからtagを取得
-		tags := tagController.GetTags(tagIDs)
 
 		return c.JSON(http.StatusOK, tags)
 	})
 
 	// タグ追加
-	e.POST("api/tsundokus/:tsundokuID/tags", func(c echo.Context) error {
-		user, err := authMiddleware.AuthUser(c.Request().Header.Get("Authorization"), userController)
-		if err != nil {
-			return err
-		}
+	api.POST("/tsundokus/:tsundokuID/tags", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
 
 		str_tsundokuID := c.Param("tsundokuID")
 		// intに変換
 		tsundokuID, err := strconv.Atoi(str_tsundokuID)
 		if err != nil {
-			return err
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
 		}
 
-		// Tagsテーブルにレコードを追加
-		tagID := tagController.CreateTag(c, user.ID)
-		// TsundokuTagsテーブルにレコードを追加
-		tsundokuTagController.CreateTsundokuTag(c, tsundokuID, user.ID, tagID)
+		// Tagsテーブルとtsundoku_tagsテーブルにレコードを追加
+		tag, err := tagController.CreateTag(c, user.ID, tsundokuID)
+		if err != nil {
+			return err
+		}
 
-		return c.JSON(http.StatusOK, "created tag")
+		return c.JSON(http.StatusCreated, []domain.Tag{tag})
 	})
 
-	// タグ削除
-	// TODO:ユーザーが管理しているかの判定
-	e.DELETE("api/tsundokus/:tsundokuID/tags/:tagID", func(c echo.Context) error {
-		str_tagID := c.Param("tagID")
-		tagID, err := strconv.Atoi(str_tagID)
+	// 積読からタグを外す。同じタグのついた他の積読はそのまま
+	api.DELETE("/tsundokus/:tsundokuID/tags/:tagID", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+
+		tsundokuID, err := strconv.Atoi(c.Param("tsundokuID"))
 		if err != nil {
-			fmt.Println(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
+		}
+		tagID, err := strconv.Atoi(c.Param("tagID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tagID")
+		}
+		if err := tagController.Delete(user.ID, tsundokuID, tagID); err != nil {
+			return err
 		}
-		tagController.Delete(tagID)
 		return c.String(http.StatusOK, "deleted tag")
 	})
 
+	// ゴミ箱の中身を取得
+	api.GET("/trash", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		trash, err := trashController.GetTrash(user.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, trash)
+	})
+
+	// ゴミ箱を空にする
+	api.DELETE("/trash", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		if err := trashController.Empty(user.ID); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "emptied trash")
+	})
+
+	// 積読をゴミ箱から戻す
+	api.POST("/trash/tsundokus/:tsundokuID/restore", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		tsundokuID, err := strconv.Atoi(c.Param("tsundokuID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
+		}
+		if err := trashController.RestoreTsundoku(user.ID, tsundokuID); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "restored tsundoku")
+	})
+
+	// 積読を完全に削除
+	api.DELETE("/trash/tsundokus/:tsundokuID", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		tsundokuID, err := strconv.Atoi(c.Param("tsundokuID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tsundokuID")
+		}
+		if err := trashController.PurgeTsundoku(user.ID, tsundokuID); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "purged tsundoku")
+	})
+
+	// タグをゴミ箱から戻す
+	api.POST("/trash/tags/:tagID/restore", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		tagID, err := strconv.Atoi(c.Param("tagID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tagID")
+		}
+		if err := trashController.RestoreTag(user.ID, tagID); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "restored tag")
+	})
+
+	// タグを完全に削除
+	api.DELETE("/trash/tags/:tagID", func(c echo.Context) error {
+		user := authMiddleware.CurrentUser(c)
+		tagID, err := strconv.Atoi(c.Param("tagID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tagID")
+		}
+		if err := trashController.PurgeTag(user.ID, tagID); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "purged tag")
+	})
+
 	port := os.Getenv("PORT")
 	// start server
 	e.Logger.Fatal(e.Start(":" + port))
 }
 
+// TRUSTED_PROXIESにカンマ区切りで書いたCIDRかIPアドレスからの接続では、X-Forwarded-Forのクライアントのアドレスを使う
+// 読めないものは使わない
+func trustedProxies() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
 func logFormat() string {
 	// Refer to https://github.com/tkuchiki/alp
 	var format string
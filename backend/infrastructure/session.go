@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+// アクセストークンの発行者
+const accessTokenIssuer = "tsuntsun"
+
+// HS256で署名したJWTのアクセストークン
+type jwtAccessTokens struct {
+	secret []byte
+}
+
+func newJWTAccessTokens(logger echo.Logger) usecase.AccessTokens {
+	secret := []byte(os.Getenv("SESSION_SECRET"))
+	if len(secret) == 0 {
+		// 再起動するとそれまでのアクセストークンは使えなくなる
+		logger.Warn("SESSION_SECRETが設定されていないので一時的な鍵を使います")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(err.Error())
+		}
+	}
+	return &jwtAccessTokens{secret: secret}
+}
+
+func (tokens *jwtAccessTokens) Issue(userID int, expiresAt time.Time) (string, error) {
+	claims := jwt.StandardClaims{
+		Issuer:    accessTokenIssuer,
+		Subject:   strconv.Itoa(userID),
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(tokens.secret)
+	if err != nil {
+		return "", domain.InternalError(err)
+	}
+	return token, nil
+}
+
+func (tokens *jwtAccessTokens) Verify(token string) (int, error) {
+	claims := jwt.StandardClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		// 署名方式を差し替えたトークンを受け付けない
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return tokens.secret, nil
+	})
+	if err != nil {
+		if validationErr, ok := err.(*jwt.ValidationError); ok && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+			return 0, domain.UnauthorizedError("access token expired")
+		}
+		return 0, domain.UnauthorizedError("invalid access token")
+	}
+	if claims.Issuer != accessTokenIssuer {
+		return 0, domain.UnauthorizedError("invalid access token")
+	}
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, domain.UnauthorizedError("invalid access token")
+	}
+	return userID, nil
+}
+
+// ACCESS_TOKEN_TTL、REFRESH_TOKEN_TTLで有効期間を変えられる(1h、720hなど)
+func sessionTTL(name string) time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || ttl <= 0 {
+		return 0
+	}
+	return ttl
+}
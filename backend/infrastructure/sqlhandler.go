@@ -3,9 +3,11 @@ package infrastructure
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	"github.com/joho/godotenv"
+	"github.com/lib/pq"
 	"github.com/yot-sailing/TSUNTSUN/domain"
 	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
 )
@@ -31,37 +33,121 @@ func NewSqlHandler() database.SqlHandler {
 	return sqlHandler
 }
 
-func (handler *SqlHandler) Create(obj interface{}) {
-	handler.db.Create(obj)
+// GORMやPostgreSQLのエラーをdomain.Errorに変換
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if gorm.IsRecordNotFoundError(err) {
+		return domain.ErrNotFound
+	}
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return &domain.Error{Kind: domain.ErrorKindConflict, Message: "already exists", Err: err}
+		case "foreign_key_violation":
+			return &domain.Error{Kind: domain.ErrorKindNotFound, Message: "referenced record not found", Err: err}
+		case "not_null_violation", "check_violation", "invalid_text_representation":
+			return &domain.Error{Kind: domain.ErrorKindValidation, Message: "invalid value", Err: err}
+		}
+	}
+	return domain.InternalError(err)
+}
+
+func (handler *SqlHandler) Create(obj interface{}) error {
+	return translateError(handler.db.Create(obj).Error)
+}
+
+func (handler *SqlHandler) FindAll(obj interface{}) error {
+	return translateError(handler.db.Find(obj).Error)
 }
 
-func (handler *SqlHandler) FindAll(obj interface{}) {
-	handler.db.Find(obj)
+// レコードが存在しなければdomain.ErrNotFoundを返す
+func (handler *SqlHandler) FindObjByID(obj interface{}, id int) error {
+	return translateError(handler.db.First(obj, id).Error)
 }
 
-func (handler *SqlHandler) DeleteById(obj interface{}, id int) {
-	handler.db.Delete(obj, id)
+func (handler *SqlHandler) Save(obj interface{}) error {
+	return translateError(handler.db.Save(obj).Error)
 }
 
-func (handler *SqlHandler) FindAllUserItem(obj interface{}, userID int) {
-	handler.db.Find(obj, "user_id=?", userID)
+func (handler *SqlHandler) Updates(obj interface{}, values map[string]interface{}) error {
+	return translateError(handler.db.Model(obj).Updates(values).Error)
 }
 
-func (handler *SqlHandler) FindObjByIDs(obj interface{}, ids []int) {
-	handler.db.Find(obj, ids)
+func (handler *SqlHandler) DeleteById(obj interface{}, id int) error {
+	return translateError(handler.db.Delete(obj, id).Error)
 }
 
-func (handler *SqlHandler) FindObjByMultiIDs(obj interface{}, tsundokuID int, userID int) {
-	handler.db.Where("tsundoku_id=? AND user_id=?", tsundokuID, userID).Find(obj)
+func (handler *SqlHandler) FindAllUserItem(obj interface{}, userID int) error {
+	return translateError(handler.db.Find(obj, "user_id=?", userID).Error)
 }
 
-func (handler *SqlHandler) FindOrCreateUser(user *domain.User, newUser *domain.User) int {
-	// fmt.Println("infra層のuserLine", userLine)
-	lineUserID := newUser.LINEID
-	result := handler.db.Where("line_id = ?", lineUserID).First(&user)
-	affect := result.RowsAffected
-	if affect == 0 {
-		handler.db.Create(&newUser)
+// columnの値がvaluesのいずれかであるユーザーのレコードを取得
+func (handler *SqlHandler) FindAllUserItemIn(obj interface{}, userID int, column string, values interface{}) error {
+	return translateError(handler.db.Where("user_id=?", userID).Where(column+" IN (?)", values).Find(obj).Error)
+}
+
+func (handler *SqlHandler) FindObjByIDs(obj interface{}, ids []int) error {
+	// 空のままだとIN ()になってしまう
+	if len(ids) == 0 {
+		return nil
+	}
+	return translateError(handler.db.Find(obj, ids).Error)
+}
+
+func (handler *SqlHandler) FindObjByMultiIDs(obj interface{}, tsundokuID int, userID int) error {
+	return translateError(handler.db.Where("tsundoku_id=? AND user_id=?", tsundokuID, userID).Find(obj).Error)
+}
+
+func (handler *SqlHandler) FindByQuery(obj interface{}, query database.Query) error {
+	db := handler.db
+	for _, condition := range query.Conditions {
+		db = db.Where(condition.SQL, condition.Args...)
+	}
+	if query.Order != "" {
+		db = db.Order(query.Order)
 	}
-	return int(affect)
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	return translateError(db.Find(obj).Error)
+}
+
+// ゴミ箱に入っているレコードを取得
+func (handler *SqlHandler) FindDeleted(obj interface{}, query string, args ...interface{}) error {
+	return translateError(handler.db.Unscoped().Where("deleted_at IS NOT NULL").Where(query, args...).Find(obj).Error)
+}
+
+// deleted_atを埋めてゴミ箱に入れ、入れた件数を返す。すでにゴミ箱に入っているものはそのまま
+func (handler *SqlHandler) SoftDelete(obj interface{}, deletedAt time.Time, query string, args ...interface{}) (int64, error) {
+	result := handler.db.Model(obj).Where(query, args...).Update("deleted_at", deletedAt)
+	return result.RowsAffected, translateError(result.Error)
+}
+
+// ゴミ箱から戻し、条件に一致した件数を返す
+func (handler *SqlHandler) Restore(obj interface{}, query string, args ...interface{}) (int64, error) {
+	result := handler.db.Unscoped().Model(obj).Where(query, args...).Update("deleted_at", nil)
+	return result.RowsAffected, translateError(result.Error)
+}
+
+// 完全に削除する
+func (handler *SqlHandler) Purge(obj interface{}, query string, args ...interface{}) error {
+	return translateError(handler.db.Unscoped().Where(query, args...).Delete(obj).Error)
+}
+
+// fnがエラーを返したらロールバックする
+func (handler *SqlHandler) Transaction(fn func(tx database.SqlHandler) error) error {
+	return handler.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&SqlHandler{db: tx})
+	})
+}
+
+func (handler *SqlHandler) Exec(sql string, args ...interface{}) error {
+	return translateError(handler.db.Exec(sql, args...).Error)
+}
+
+// SQLの結果をobjectに読み込む
+func (handler *SqlHandler) Raw(obj interface{}, sql string, args ...interface{}) error {
+	return translateError(handler.db.Raw(sql, args...).Scan(obj).Error)
 }
@@ -0,0 +1,39 @@
+package infrastructure
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// DATABASE_URLのPostgreSQLに使い捨てのスキーマを作り、積読とタグ、セッションと個人用アクセストークンのテーブルを用意する
+// 他のテーブルに触らないように、接続を一つにしてsearch_pathをそのスキーマにする
+func openTestSchema(tb testing.TB, prefix string) *gorm.DB {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		tb.Skip("DATABASE_URL is not set")
+	}
+	db, err := gorm.Open("postgres", url)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	db.DB().SetMaxOpenConns(1)
+	schema := fmt.Sprintf("%s_%d", prefix, os.Getpid())
+	tb.Cleanup(func() {
+		db.Exec("DROP SCHEMA IF EXISTS " + schema + " CASCADE")
+		db.Close()
+	})
+	for _, sql := range []string{"CREATE SCHEMA " + schema, "SET search_path TO " + schema} {
+		if err := db.Exec(sql).Error; err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if err := db.AutoMigrate(domain.User{}, domain.Tsundoku{}, domain.Tag{}, domain.TsundokuTag{}, domain.Session{}, domain.PersonalAccessToken{}).Error; err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
@@ -0,0 +1,86 @@
+package infrastructure
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+)
+
+const (
+	benchTsundokus       = 200
+	benchTags            = 20
+	benchTagsPerTsundoku = 3
+)
+
+// 使い捨てのスキーマにタグのついた積読を入れる
+func seedTagBenchmark(b *testing.B) (*SqlHandler, int, []int) {
+	db := openTestSchema(b, "bench_tags")
+
+	user := domain.User{Name: "bench"}
+	if err := db.Create(&user).Error; err != nil {
+		b.Fatal(err)
+	}
+	tags := make([]domain.Tag, benchTags)
+	for i := range tags {
+		tags[i] = domain.Tag{UserID: user.ID, Name: fmt.Sprintf("tag%d", i)}
+		if err := db.Create(&tags[i]).Error; err != nil {
+			b.Fatal(err)
+		}
+	}
+	ids := make([]int, benchTsundokus)
+	for i := range ids {
+		tsundoku := domain.Tsundoku{UserID: user.ID, Category: "site", Title: fmt.Sprintf("tsundoku%d", i)}
+		if err := db.Create(&tsundoku).Error; err != nil {
+			b.Fatal(err)
+		}
+		ids[i] = tsundoku.ID
+		for j := 0; j < benchTagsPerTsundoku; j++ {
+			tsundokuTag := domain.TsundokuTag{TsundokuID: tsundoku.ID, TagID: tags[(i+j)%benchTags].ID, UserID: user.ID}
+			if err := db.Create(&tsundokuTag).Error; err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	return &SqlHandler{db: db}, user.ID, ids
+}
+
+// 以前のように積読1件ごとにタグ付けとタグを取得する(N+1)
+func BenchmarkSelectTagsPerTsundoku(b *testing.B) {
+	sqlHandler, userID, ids := seedTagBenchmark(b)
+	tsundokuTagRepository := &database.TsundokuTagRepository{SqlHandler: sqlHandler}
+	tagRepository := &database.TagRepository{SqlHandler: sqlHandler}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, id := range ids {
+			tsundokuTags, err := tsundokuTagRepository.SelectByMultiIDs(id, userID)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var tagIDs []int
+			for _, tsundokuTag := range tsundokuTags {
+				tagIDs = append(tagIDs, tsundokuTag.TagID)
+			}
+			if _, err := tagRepository.Select(tagIDs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// 一覧の積読のタグを1回のクエリで取得する
+func BenchmarkSelectTagsBatched(b *testing.B) {
+	sqlHandler, userID, ids := seedTagBenchmark(b)
+	repository := &database.TsundokuRepository{SqlHandler: sqlHandler}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tags, err := repository.SelectTags(userID, ids)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(tags) != len(ids) {
+			b.Fatalf("got tags for %d tsundokus, want %d", len(tags), len(ids))
+		}
+	}
+}
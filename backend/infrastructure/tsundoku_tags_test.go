@@ -0,0 +1,72 @@
+package infrastructure
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+)
+
+// 積読からタグを外すとタグ付けは完全に消え、ゴミ箱に残らない
+func TestTsundokuTagDeleteByUser(t *testing.T) {
+	db := openTestSchema(t, "test_tsundoku_tags")
+	alice, bob := domain.User{Name: "alice"}, domain.User{Name: "bob"}
+	for _, user := range []*domain.User{&alice, &bob} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	tag := domain.Tag{UserID: alice.ID, Name: "Go"}
+	if err := db.Create(&tag).Error; err != nil {
+		t.Fatal(err)
+	}
+	ids := []int{}
+	for _, title := range []string{"first", "second"} {
+		tsundoku := domain.Tsundoku{UserID: alice.ID, Category: domain.CategorySite, Title: title}
+		if err := db.Create(&tsundoku).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Create(&domain.TsundokuTag{TsundokuID: tsundoku.ID, TagID: tag.ID, UserID: alice.ID}).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, tsundoku.ID)
+	}
+	repository := &database.TsundokuTagRepository{SqlHandler: &SqlHandler{db: db}}
+	countLinks := func(tsundokuID int) int {
+		count := 0
+		if err := db.Unscoped().Model(&domain.TsundokuTag{}).Where("tsundoku_id = ?", tsundokuID).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	if err := repository.DeleteByUser(bob.ID, ids[0], tag.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("another user's link: got error %v, want %v", err, domain.ErrNotFound)
+	}
+	if err := repository.DeleteByUser(alice.ID, ids[0], tag.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count := countLinks(ids[0]); count != 0 {
+		t.Errorf("got %d links including trashed ones, want 0", count)
+	}
+	if count := countLinks(ids[1]); count != 1 {
+		t.Errorf("other tsundoku: got %d links, want 1", count)
+	}
+	if err := db.First(&domain.Tag{}, tag.ID).Error; err != nil {
+		t.Errorf("tag: %v", err)
+	}
+	if err := repository.DeleteByUser(alice.ID, ids[0], tag.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("removed link: got error %v, want %v", err, domain.ErrNotFound)
+	}
+
+	// 付け直しても、すでについていても一つだけ
+	for i := 0; i < 2; i++ {
+		if err := repository.Store(domain.TsundokuTag{TsundokuID: ids[0], TagID: tag.ID, UserID: alice.ID}); err != nil {
+			t.Fatalf("store: %v", err)
+		}
+	}
+	if count := countLinks(ids[0]); count != 1 {
+		t.Errorf("after storing again: got %d links, want 1", count)
+	}
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/body"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+type AccountController struct {
+	Interactor usecase.AccountInteractor
+}
+
+func NewAccountController(sqlHandler database.SqlHandler, identityProviders usecase.IdentityProviders, gracePeriod time.Duration) *AccountController {
+	return &AccountController{
+		Interactor: usecase.AccountInteractor{
+			AccountRepository: &database.AccountRepository{
+				SqlHandler: sqlHandler,
+			},
+			IdentityProviders: identityProviders,
+			GracePeriod:       gracePeriod,
+		},
+	}
+}
+
+// すべてのデータをzipにまとめる
+func (controller *AccountController) Export(userID int) ([]byte, error) {
+	export, err := controller.Interactor.Export(userID)
+	if err != nil {
+		return nil, err
+	}
+	return exportArchive(export)
+}
+
+// 退会を予約して、削除する日時と書き出したデータのzipを返す
+// IDプロバイダーのアクセストークンを無効にするので、providerとaccessTokenを送る
+func (controller *AccountController) ScheduleDeletion(c echo.Context, userID int) (time.Time, []byte, error) {
+	req := body.DeleteAccountRequest{}
+	if err := c.Bind(&req); err != nil {
+		return time.Time{}, nil, err
+	}
+	export, err := controller.Interactor.ScheduleDeletion(userID, req.Provider, req.AccessToken)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	archive, err := exportArchive(export)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return *export.User.DeletionScheduledAt, archive, nil
+}
+
+func (controller *AccountController) PurgeScheduled() error {
+	return controller.Interactor.PurgeScheduled()
+}
+
+// 種類ごとにJSONファイルにしてzipにまとめる
+func exportArchive(export domain.AccountExport) ([]byte, error) {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"user.json", export.User},
+		{"identities.json", export.Identities},
+		{"tsundokus.json", export.Tsundokus},
+		{"tags.json", export.Tags},
+		{"tsundoku_tags.json", export.TsundokuTags},
+		{"progress_logs.json", export.ProgressLogs},
+		{"reading_sessions.json", export.ReadingSessions},
+		{"personal_access_tokens.json", export.PersonalAccessTokens},
+	}
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, file := range files {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return nil, domain.InternalError(err)
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, domain.InternalError(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, domain.InternalError(err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+type IdentityController struct {
+	Interactor usecase.IdentityInteractor
+}
+
+func NewIdentityController(sqlHandler database.SqlHandler, identityProviders usecase.IdentityProviders) *IdentityController {
+	return &IdentityController{
+		Interactor: usecase.IdentityInteractor{
+			IdentityProviders: identityProviders,
+			IdentityRepository: &database.IdentityRepository{
+				SqlHandler: sqlHandler,
+			},
+		},
+	}
+}
+
+func (controller *IdentityController) GetIdentities(userID int) ([]domain.Identity, error) {
+	return controller.Interactor.GetInfoByUser(userID)
+}
+
+// フォームのproviderと、id_tokenとnonceかaccess_tokenで確認したアカウントを紐づける
+// AuthorizationヘッダーはこのAPIのトークンに使っているので、アクセストークンもフォームで受け取る
+func (controller *IdentityController) Link(c echo.Context, userID int) (domain.Identity, error) {
+	return controller.Interactor.Link(userID, providerCredential(c, c.FormValue("access_token")))
+}
+
+func (controller *IdentityController) Unlink(userID int, identityID int) error {
+	return controller.Interactor.Unlink(userID, identityID)
+}
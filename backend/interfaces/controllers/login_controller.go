@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+type LoginController struct {
+	Interactor usecase.LoginInteractor
+}
+
+func NewLoginController(sqlHandler database.SqlHandler, identityProviders usecase.IdentityProviders, adminSubjects []domain.Identity) *LoginController {
+	return &LoginController{
+		Interactor: usecase.LoginInteractor{
+			IdentityProviders: identityProviders,
+			AdminSubjects:     adminSubjects,
+			UserRepository: &database.UserRepository{
+				SqlHandler: sqlHandler,
+			},
+		},
+	}
+}
+
+// フォームのid_tokenとnonce、またはAuthorizationヘッダーの"Bearer <IDプロバイダーのアクセストークン>"でログインする
+// providerでIDプロバイダーを選べる(省略するとデフォルトのもの)
+func (controller *LoginController) Login(c echo.Context) (domain.User, error) {
+	return controller.Interactor.Login(providerCredential(c, bearerToken(c)))
+}
+
+func (controller *LoginController) Logout(c echo.Context) error {
+	return controller.Interactor.Logout(c.FormValue("provider"), bearerToken(c))
+}
+
+func bearerToken(c echo.Context) string {
+	return strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+}
+
+// フォームからIDプロバイダーで本人確認するための値を読む。id_tokenが無ければaccessTokenを使う
+func providerCredential(c echo.Context, accessToken string) usecase.ProviderCredential {
+	credential := usecase.ProviderCredential{
+		Provider: c.FormValue("provider"),
+		IDToken:  c.FormValue("id_token"),
+		Nonce:    c.FormValue("nonce"),
+	}
+	if credential.IDToken == "" {
+		credential.AccessToken = accessToken
+	}
+	return credential
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+// 一覧の取得範囲のパラメータを変換
+// sort=deadline : 並び順。-deadlineのように-をつけると逆順
+// limit=50 : 1ページの件数
+// cursor=... : 前のページのnext_cursor
+func pageParam(c echo.Context) (usecase.Page, error) {
+	page := usecase.Page{Sort: c.QueryParam("sort")}
+	if strings.HasPrefix(page.Sort, "-") {
+		page.Sort, page.Desc = page.Sort[1:], true
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		var err error
+		if page.Limit, err = strconv.Atoi(limit); err != nil || page.Limit < 1 {
+			return page, domain.ValidationError("limit must be a positive number")
+		}
+	}
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		var err error
+		if page.After, err = usecase.DecodeCursor(cursor); err != nil {
+			return page, err
+		}
+	}
+	return page, nil
+}
+
+// limitかcursorを指定したときはページに分けて返す。指定しなければ今まで通り全件を配列で返す
+func IsPaged(c echo.Context) bool {
+	return c.QueryParam("limit") != "" || c.QueryParam("cursor") != ""
+}
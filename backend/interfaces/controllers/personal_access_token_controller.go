@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/body"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+type PersonalAccessTokenController struct {
+	Interactor usecase.PersonalAccessTokenInteractor
+}
+
+func NewPersonalAccessTokenController(sqlHandler database.SqlHandler) *PersonalAccessTokenController {
+	return &PersonalAccessTokenController{
+		Interactor: usecase.PersonalAccessTokenInteractor{
+			PersonalAccessTokenRepository: &database.PersonalAccessTokenRepository{
+				SqlHandler: sqlHandler,
+			},
+		},
+	}
+}
+
+func (controller *PersonalAccessTokenController) CreateToken(c echo.Context, userID int) (body.PersonalAccessTokenCreateResponse, error) {
+	req := body.PersonalAccessTokenCreateRequest{}
+	if err := c.Bind(&req); err != nil {
+		return body.PersonalAccessTokenCreateResponse{}, err
+	}
+	token, secret, err := controller.Interactor.Add(userID, req.Name, req.Scopes)
+	if err != nil {
+		return body.PersonalAccessTokenCreateResponse{}, err
+	}
+	return body.PersonalAccessTokenCreateResponse{PersonalAccessToken: token, Token: secret}, nil
+}
+
+func (controller *PersonalAccessTokenController) GetTokens(userID int) ([]domain.PersonalAccessToken, error) {
+	return controller.Interactor.GetInfoByUser(userID)
+}
+
+func (controller *PersonalAccessTokenController) Delete(userID int, tokenID int) error {
+	return controller.Interactor.Delete(userID, tokenID)
+}
+
+// Authorizationヘッダーが個人用アクセストークンか
+func IsPersonalAccessToken(authorization string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(authorization, "Bearer "), domain.PersonalAccessTokenPrefix)
+}
+
+// Authorizationヘッダーの"Bearer <個人用アクセストークン>"を確かめる。ipは記録のため
+func (controller *PersonalAccessTokenController) Authenticate(authorization string, ip string) (domain.PersonalAccessToken, error) {
+	return controller.Interactor.Authenticate(strings.TrimPrefix(authorization, "Bearer "), ip)
+}
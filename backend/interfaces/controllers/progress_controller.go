@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/body"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+type ProgressController struct {
+	Interactor usecase.ProgressInteractor
+}
+
+func NewProgressController(sqlHandler database.SqlHandler) *ProgressController {
+	return &ProgressController{
+		Interactor: usecase.ProgressInteractor{
+			TsundokuRepository: &database.TsundokuRepository{
+				SqlHandler: sqlHandler,
+			},
+			ProgressRepository: &database.ProgressRepository{
+				SqlHandler: sqlHandler,
+			},
+		},
+	}
+}
+
+func (controller *ProgressController) Record(c echo.Context, userID int, tsundokuID int) (domain.Progress, error) {
+	req := body.ProgressRequest{}
+	if err := c.Bind(&req); err != nil {
+		return domain.Progress{}, err
+	}
+	return controller.Interactor.Record(userID, tsundokuID, req.Page, req.Minutes)
+}
+
+func (controller *ProgressController) Get(userID int, tsundokuID int) (domain.Progress, error) {
+	return controller.Interactor.Get(userID, tsundokuID)
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/body"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+type ReadingSessionController struct {
+	Interactor usecase.ReadingSessionInteractor
+}
+
+func NewReadingSessionController(sqlHandler database.SqlHandler, speeds usecase.ReadingSpeeds) *ReadingSessionController {
+	return &ReadingSessionController{
+		Interactor: newReadingSessionInteractor(sqlHandler, speeds),
+	}
+}
+
+// トランザクションの中ではtxを使うリポジトリで作り直す
+func newReadingSessionInteractor(sqlHandler database.SqlHandler, speeds usecase.ReadingSpeeds) usecase.ReadingSessionInteractor {
+	return usecase.ReadingSessionInteractor{
+		TsundokuRepository: &database.TsundokuRepository{
+			SqlHandler: sqlHandler,
+		},
+		ReadingSessionRepository: &database.ReadingSessionRepository{
+			SqlHandler: sqlHandler,
+		},
+		ProgressRepository: &database.ProgressRepository{
+			SqlHandler: sqlHandler,
+		},
+		UserRepository: &database.UserRepository{
+			SqlHandler: sqlHandler,
+		},
+		Speeds: speeds,
+		Transaction: func(fn func(tx *usecase.ReadingSessionInteractor) error) error {
+			return sqlHandler.Transaction(func(tx database.SqlHandler) error {
+				interactor := newReadingSessionInteractor(tx, speeds)
+				return fn(&interactor)
+			})
+		},
+	}
+}
+
+func (controller *ReadingSessionController) Start(userID int, tsundokuID int) (domain.ReadingSession, error) {
+	return controller.Interactor.Start(userID, tsundokuID)
+}
+
+func (controller *ReadingSessionController) Stop(c echo.Context, userID int, tsundokuID int) (domain.ReadingSession, error) {
+	req := body.StopReadingSessionRequest{}
+	if err := c.Bind(&req); err != nil {
+		return domain.ReadingSession{}, err
+	}
+	return controller.Interactor.Stop(userID, tsundokuID, usecase.ReadingSessionStop{Page: req.Page, Finished: req.Finished})
+}
+
+func (controller *ReadingSessionController) GetReadingSessions(userID int, tsundokuID int) ([]domain.ReadingSession, error) {
+	return controller.Interactor.GetInfoByTsundoku(userID, tsundokuID)
+}
+
+func (controller *ReadingSessionController) Report(userID int) (domain.ReadingSpeedReport, error) {
+	return controller.Interactor.Report(userID)
+}
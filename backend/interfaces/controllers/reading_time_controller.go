@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+type ReadingTimeController struct {
+	Interactor usecase.ReadingTimeInteractor
+}
+
+func NewReadingTimeController(sqlHandler database.SqlHandler, pageFetcher usecase.PageFetcher, speeds usecase.ReadingSpeeds) *ReadingTimeController {
+	return &ReadingTimeController{
+		Interactor: usecase.ReadingTimeInteractor{
+			ReadingTimeRepository: &database.TsundokuRepository{
+				SqlHandler: sqlHandler,
+			},
+			ReadingSessionRepository: &database.ReadingSessionRepository{
+				SqlHandler: sqlHandler,
+			},
+			UserRepository: &database.UserRepository{
+				SqlHandler: sqlHandler,
+			},
+			PageFetcher: pageFetcher,
+			Speeds:      speeds,
+		},
+	}
+}
+
+func (controller *ReadingTimeController) EstimatePending() error {
+	return controller.Interactor.EstimatePending()
+}
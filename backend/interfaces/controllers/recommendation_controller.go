@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+type RecommendationController struct {
+	Interactor usecase.RecommendationInteractor
+}
+
+func NewRecommendationController(sqlHandler database.SqlHandler) *RecommendationController {
+	return &RecommendationController{
+		Interactor: usecase.RecommendationInteractor{
+			TsundokuRepository: &database.TsundokuRepository{
+				SqlHandler: sqlHandler,
+			},
+			UserRepository: &database.UserRepository{
+				SqlHandler: sqlHandler,
+			},
+		},
+	}
+}
+
+// ?count=3&category=book,site で今日のおすすめを取得する
+func (controller *RecommendationController) Recommend(c echo.Context, userID int) ([]domain.Recommendation, error) {
+	count := 0
+	if param := c.QueryParam("count"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return nil, domain.ValidationError("count must be a number")
+		}
+		count = n
+	}
+	return controller.Interactor.Recommend(userID, count, splitParam(c.QueryParam("category")))
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/body"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+type SessionController struct {
+	Interactor usecase.SessionInteractor
+}
+
+// ttlが0ならデフォルトの有効期間
+func NewSessionController(sqlHandler database.SqlHandler, accessTokens usecase.AccessTokens, accessTokenTTL, refreshTokenTTL time.Duration) *SessionController {
+	return &SessionController{
+		Interactor: usecase.SessionInteractor{
+			SessionRepository: &database.SessionRepository{
+				SqlHandler: sqlHandler,
+			},
+			AccessTokens:    accessTokens,
+			AccessTokenTTL:  accessTokenTTL,
+			RefreshTokenTTL: refreshTokenTTL,
+		},
+	}
+}
+
+func (controller *SessionController) Start(userID int) (domain.SessionToken, error) {
+	return controller.Interactor.Start(userID)
+}
+
+func (controller *SessionController) Refresh(c echo.Context) (domain.SessionToken, error) {
+	req := body.RefreshTokenRequest{}
+	if err := c.Bind(&req); err != nil {
+		return domain.SessionToken{}, err
+	}
+	return controller.Interactor.Refresh(req.RefreshToken)
+}
+
+func (controller *SessionController) End(c echo.Context) error {
+	req := body.RefreshTokenRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	return controller.Interactor.End(req.RefreshToken)
+}
+
+// Authorizationヘッダーの"Bearer <アクセストークン>"を検証して利用者のIDを返す
+func (controller *SessionController) Authenticate(authorization string) (int, error) {
+	return controller.Interactor.Authenticate(strings.TrimPrefix(authorization, "Bearer "))
+}
+
+func (controller *SessionController) PurgeExpired() error {
+	return controller.Interactor.PurgeExpired()
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/body"
 	"github.com/yot-sailing/TSUNTSUN/domain"
 	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
 	"github.com/yot-sailing/TSUNTSUN/usecase"
@@ -17,29 +18,59 @@ func NewTagController(sqlHandler database.SqlHandler) *TagController {
 			TagRepository: &database.TagRepository{
 				SqlHandler: sqlHandler,
 			},
+			TsundokuRepository: &database.TsundokuRepository{
+				SqlHandler: sqlHandler,
+			},
+			TsundokuTagRepository: &database.TsundokuTagRepository{
+				SqlHandler: sqlHandler,
+			},
 		},
 	}
 }
 
-func (controller *TagController) CreateTag(c echo.Context, userID int) int {
+// ユーザーが管理している積読にタグをつける
+func (controller *TagController) CreateTag(c echo.Context, userID int, tsundokuID int) (domain.Tag, error) {
 	tag := domain.Tag{}
-	c.Bind(&tag)
-	tagID := controller.Interactor.Add(tag)
+	if err := c.Bind(&tag); err != nil {
+		return domain.Tag{}, err
+	}
+	return controller.Interactor.AddToTsundoku(userID, tsundokuID, tag.Name)
+}
+
+// ユーザーのタグを取得。sortはcreatedかname。sort, limit, cursorはpageParamを参照
+func (controller *TagController) GetUserTags(c echo.Context, userID int) ([]domain.Tag, string, error) {
+	page, err := pageParam(c)
+	if err != nil {
+		return nil, "", err
+	}
+	return controller.Interactor.GetInfoByUser(userID, page)
+}
 
-	var tagIDs []int
-	tagIDs = append(tagIDs, tagID)
-	tags := controller.GetTags(tagIDs)
+func (controller *TagController) Rename(c echo.Context, userID int, tagID int) (domain.Tag, error) {
+	req := body.TagRenameRequest{}
+	if err := c.Bind(&req); err != nil {
+		return domain.Tag{}, err
+	}
+	return controller.Interactor.Rename(userID, tagID, req.Name)
+}
 
-	c.JSON(201, tags)
-	return tagID
+func (controller *TagController) Merge(c echo.Context, userID int) (domain.Tag, error) {
+	req := body.TagMergeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return domain.Tag{}, err
+	}
+	return controller.Interactor.Merge(userID, req.SourceID, req.TargetID)
 }
 
 // 複数のtagIDからタグを取得
-func (controller *TagController) GetTags(tagIDs []int) []domain.Tag {
-	res := controller.Interactor.GetInfo(tagIDs)
-	return res
+func (controller *TagController) GetTags(tagIDs []int) ([]domain.Tag, error) {
+	return controller.Interactor.GetInfo(tagIDs)
+}
+
+func (controller *TagController) Delete(userID int, tsundokuID int, tagID int) error {
+	return controller.Interactor.DeleteFromTsundoku(userID, tsundokuID, tagID)
 }
 
-func (controller *TagController) Delete(id int) {
-	controller.Interactor.Delete(id)
+func (controller *TagController) DeleteTag(userID int, tagID int) error {
+	return controller.Interactor.Delete(userID, tagID)
 }
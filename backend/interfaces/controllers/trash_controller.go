@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+type TrashController struct {
+	Interactor usecase.TrashInteractor
+}
+
+func NewTrashController(sqlHandler database.SqlHandler) *TrashController {
+	return &TrashController{
+		Interactor: usecase.TrashInteractor{
+			TsundokuRepository: &database.TsundokuRepository{
+				SqlHandler: sqlHandler,
+			},
+			TagRepository: &database.TagRepository{
+				SqlHandler: sqlHandler,
+			},
+		},
+	}
+}
+
+func (controller *TrashController) GetTrash(userID int) (domain.Trash, error) {
+	return controller.Interactor.GetInfo(userID)
+}
+
+func (controller *TrashController) RestoreTsundoku(userID int, tsundokuID int) error {
+	return controller.Interactor.RestoreTsundoku(userID, tsundokuID)
+}
+
+func (controller *TrashController) PurgeTsundoku(userID int, tsundokuID int) error {
+	return controller.Interactor.PurgeTsundoku(userID, tsundokuID)
+}
+
+func (controller *TrashController) RestoreTag(userID int, tagID int) error {
+	return controller.Interactor.RestoreTag(userID, tagID)
+}
+
+func (controller *TrashController) PurgeTag(userID int, tagID int) error {
+	return controller.Interactor.PurgeTag(userID, tagID)
+}
+
+func (controller *TrashController) Empty(userID int) error {
+	return controller.Interactor.Empty(userID)
+}
+
+func (controller *TrashController) PurgeExpired(retention time.Duration) error {
+	return controller.Interactor.PurgeExpired(retention)
+}
@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	"github.com/labstack/echo"
 	"github.com/yot-sailing/TSUNTSUN/domain"
 	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
 	"github.com/yot-sailing/TSUNTSUN/usecase"
@@ -21,30 +20,12 @@ func NewTsundokuTagController(sqlHandler database.SqlHandler) *TsundokuTagContro
 	}
 }
 
-func (controller *TsundokuTagController) CreateTsundokuTag(c echo.Context, tsundokuID int, userID int, tagID int) {
-	tsundokuTag := domain.TsundokuTag{
-		TsundokuID: tsundokuID,
-		TagID:      tagID,
-		UserID:     userID,
-	}
-	controller.Interactor.Add(tsundokuTag)
-	createdTsundokuTag := controller.Interactor.GetInfo(userID)
-	c.JSON(201, createdTsundokuTag)
-	return
-}
-
 // userIDからレコードを取得
-func (controller *TsundokuTagController) GetTsundokuTags(userID int) []domain.TsundokuTag {
-	res := controller.Interactor.GetInfo(userID)
-	return res
+func (controller *TsundokuTagController) GetTsundokuTags(userID int) ([]domain.TsundokuTag, error) {
+	return controller.Interactor.GetInfo(userID)
 }
 
 // tsundokuIDとuserIDからレコードを取得
-func (controller *TsundokuTagController) GetTsundokuTagsByTsundokuIDandUserID(tsundokuID, userID int) []domain.TsundokuTag {
-	res := controller.Interactor.GetInfoByMultiIDs(tsundokuID, userID)
-	return res
-}
-
-func (controller *TsundokuTagController) Delete(id int) {
-	controller.Interactor.Delete(id)
+func (controller *TsundokuTagController) GetTsundokuTagsByTsundokuIDandUserID(tsundokuID, userID int) ([]domain.TsundokuTag, error) {
+	return controller.Interactor.GetInfoByMultiIDs(tsundokuID, userID)
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/body"
 	"github.com/yot-sailing/TSUNTSUN/domain"
 	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
 	"github.com/yot-sailing/TSUNTSUN/usecase"
@@ -21,42 +22,217 @@ func NewTsundokuController(sqlHandler database.SqlHandler) *TsundokuController {
 			TsundokuRepository: &database.TsundokuRepository{
 				SqlHandler: sqlHandler,
 			},
+			ProgressRepository: &database.ProgressRepository{
+				SqlHandler: sqlHandler,
+			},
 		},
 	}
 }
 
-func (controller *TsundokuController) CreateTsundoku(c echo.Context, userID int) {
-	tsundoku := domain.Tsundoku{}
-	t, _ := time.Parse("2006-01-02", c.FormValue("deadline"))
-	c.Bind(&tsundoku)
-	tsundoku.Deadline = t
-	tsundoku.UserID = userID
-	controller.Interactor.Add(tsundoku)
-	createdTsundokus := controller.Interactor.GetInfo(userID)
-	c.JSON(201, createdTsundokus)
-	return
+// YYYY-MM-DDの締め切りを変換。空文字なら締め切りなし
+func parseDeadline(deadline string) (time.Time, error) {
+	if deadline == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("2006-01-02", deadline)
+	if err != nil {
+		return time.Time{}, domain.ValidationError("deadline must be YYYY-MM-DD")
+	}
+	return t, nil
+}
+
+func (controller *TsundokuController) CreateTsundoku(c echo.Context, userID int) (domain.Tsundoku, error) {
+	req := body.TsundokuCreateRequest{}
+	if err := c.Bind(&req); err != nil {
+		return domain.Tsundoku{}, err
+	}
+	deadline, err := parseDeadline(req.Deadline)
+	if err != nil {
+		return domain.Tsundoku{}, err
+	}
+	requiredMinutes, err := domain.ParseRequiredTime(req.RequiredTime)
+	if err != nil {
+		return domain.Tsundoku{}, err
+	}
+
+	tsundoku := domain.Tsundoku{
+		UserID:          userID,
+		Category:        req.Category,
+		Title:           req.Title,
+		Author:          req.Author,
+		URL:             req.URL,
+		Note:            req.Note,
+		Deadline:        deadline,
+		RequiredMinutes: requiredMinutes,
+		Priority:        req.Priority,
+		TotalPages:      req.TotalPages,
+		Language:        req.Language,
+	}
+	return controller.Interactor.Add(tsundoku)
 }
 
-func (controller *TsundokuController) GetFreeTsundoku(c echo.Context, userID int, free_time int) []domain.Tsundoku {
-	res := controller.Interactor.GetInfo(userID)
+func (controller *TsundokuController) GetFreeTsundoku(c echo.Context, userID int, free_time int) ([]domain.Tsundoku, error) {
+	res, _, err := controller.Interactor.GetInfoByFilter(userID, usecase.TsundokuFilter{Statuses: domain.UnfinishedStatuses}, usecase.Page{})
+	if err != nil {
+		return nil, err
+	}
 	results := []domain.Tsundoku{}
 	for _, element := range res {
-		if element.Category == "site" {
-			need_time := strings.Replace(element.RequiredTime, "min", "", -1)
-			required_time, _ := strconv.Atoi(need_time)
-			if free_time >= required_time {
-				results = append(results, element)
-			}
+		// 読む時間が分からないものは勧めない
+		if element.Category == domain.CategorySite && element.RequiredMinutes > 0 && element.RequiredMinutes <= free_time {
+			results = append(results, element)
+		}
+	}
+	return results, nil
+}
+
+// ?minutes=45&category=site で空き時間に読む積読を計画する
+func (controller *TsundokuController) Plan(c echo.Context, userID int) (domain.Plan, error) {
+	minutes, err := strconv.Atoi(c.QueryParam("minutes"))
+	if err != nil {
+		return domain.Plan{}, domain.ValidationError("minutes must be a number")
+	}
+	return controller.Interactor.Plan(userID, minutes, splitParam(c.QueryParam("category")))
+}
+
+func (controller *TsundokuController) GetTsundoku(userID int) ([]domain.Tsundoku, error) {
+	return controller.Interactor.GetInfo(userID)
+}
+
+func (controller *TsundokuController) GetTags(userID int, tsundokuID int) ([]domain.Tag, error) {
+	return controller.Interactor.GetTags(userID, tsundokuID)
+}
+
+// 送られてきた項目だけを更新する
+func (controller *TsundokuController) UpdateTsundoku(c echo.Context, userID int, tsundokuID int) (domain.Tsundoku, error) {
+	req := body.TsundokuUpdateRequest{}
+	if err := c.Bind(&req); err != nil {
+		return domain.Tsundoku{}, err
+	}
+
+	update := usecase.TsundokuUpdate{
+		Category:   req.Category,
+		Title:      req.Title,
+		Author:     req.Author,
+		URL:        req.URL,
+		Note:       req.Note,
+		Priority:   req.Priority,
+		TotalPages: req.TotalPages,
+		Language:   req.Language,
+	}
+	if req.Deadline != nil {
+		// 空文字なら締め切りを外す
+		deadline, err := parseDeadline(*req.Deadline)
+		if err != nil {
+			return domain.Tsundoku{}, err
+		}
+		update.Deadline = &deadline
+	}
+	if req.RequiredTime != nil {
+		// 空文字なら不明に戻す
+		requiredMinutes, err := domain.ParseRequiredTime(*req.RequiredTime)
+		if err != nil {
+			return domain.Tsundoku{}, err
+		}
+		update.RequiredMinutes = &requiredMinutes
+	}
+
+	return controller.Interactor.Update(userID, tsundokuID, update)
+}
+
+// クエリパラメータで絞り込んで積読を取得
+// status=unread,reading : 読書状態
+// tags=Go,DB&tag_mode=and : タグ(tag_modeはorかand。デフォルトはor)
+// category=book,site : カテゴリ
+// deadline_before, deadline_after, created_before, created_after : YYYY-MM-DDかRFC3339
+// has_url=true : URLがあるか
+// sort, limit, cursorはpageParamを参照。sortはcreated, deadline, requiredTime, title
+func (controller *TsundokuController) GetTsundokuByFilter(c echo.Context, userID int) ([]domain.Tsundoku, string, error) {
+	filter := usecase.TsundokuFilter{
+		Statuses:   splitParam(c.QueryParam("status")),
+		Tags:       splitParam(c.QueryParam("tags")),
+		TagMatch:   c.QueryParam("tag_mode"),
+		Categories: splitParam(c.QueryParam("category")),
+	}
+
+	var err error
+	if filter.DeadlineBefore, err = timeParam(c, "deadline_before", true); err != nil {
+		return nil, "", err
+	}
+	if filter.DeadlineAfter, err = timeParam(c, "deadline_after", false); err != nil {
+		return nil, "", err
+	}
+	if filter.CreatedBefore, err = timeParam(c, "created_before", true); err != nil {
+		return nil, "", err
+	}
+	if filter.CreatedAfter, err = timeParam(c, "created_after", false); err != nil {
+		return nil, "", err
+	}
+	if hasURL := c.QueryParam("has_url"); hasURL != "" {
+		b, err := strconv.ParseBool(hasURL)
+		if err != nil {
+			return nil, "", domain.ValidationError("has_url must be true or false")
+		}
+		filter.HasURL = &b
+	}
+
+	page, err := pageParam(c)
+	if err != nil {
+		return nil, "", err
+	}
+	return controller.Interactor.GetInfoByFilter(userID, filter, page)
+}
+
+// カンマ区切りのパラメータを重複と空を除いて分割
+func splitParam(param string) []string {
+	var values []string
+	seen := map[string]bool{}
+	for _, value := range strings.Split(param, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		values = append(values, value)
+	}
+	return values
+}
+
+// 日時のパラメータを変換。YYYY-MM-DDのときendOfDayならその日の終わりまでを含める
+func timeParam(c echo.Context, name string, endOfDay bool) (*time.Time, error) {
+	param := c.QueryParam(name)
+	if param == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, param); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", param)
+	if err != nil {
+		return nil, domain.ValidationError(name + " must be YYYY-MM-DD or RFC3339")
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// ?q=並行処理&limit=20 で検索
+func (controller *TsundokuController) Search(c echo.Context, userID int) ([]domain.SearchResult, error) {
+	limit := 0
+	if param := c.QueryParam("limit"); param != "" {
+		var err error
+		if limit, err = strconv.Atoi(param); err != nil {
+			return nil, domain.ValidationError("limit must be a number")
 		}
 	}
-	return results
+	return controller.Interactor.Search(userID, c.QueryParam("q"), limit)
 }
 
-func (controller *TsundokuController) GetTsundoku(userID int) []domain.Tsundoku {
-	tsundokus := controller.Interactor.GetInfo(userID)
-	return tsundokus
+func (controller *TsundokuController) ChangeStatus(userID int, tsundokuID int, status string) (domain.Tsundoku, error) {
+	return controller.Interactor.ChangeStatus(userID, tsundokuID, status)
 }
 
-func (controller *TsundokuController) Delete(id int) {
-	controller.Interactor.Delete(id)
+func (controller *TsundokuController) Delete(userID int, tsundokuID int) error {
+	return controller.Interactor.Delete(userID, tsundokuID)
 }
@@ -13,35 +13,60 @@ type UserController struct {
 }
 
 func NewUserController(sqlHandler database.SqlHandler) *UserController {
+	userRepository := &database.UserRepository{
+		SqlHandler: sqlHandler,
+	}
 	return &UserController{
 		Interactor: usecase.UserInteractor{
-			UserRepository: &database.UserRepository{
-				SqlHandler: sqlHandler,
+			UserRepository: userRepository,
+			Authorizer: usecase.Authorizer{
+				UserRepository: userRepository,
 			},
 		},
 	}
 }
 
-func (controller *UserController) Create(c echo.Context) {
-	u := domain.User{}
-	c.Bind(&u)
-	controller.Interactor.Add(u)
-	createdUsers := controller.Interactor.GetInfo()
-	c.JSON(201, createdUsers)
-	return
+func (controller *UserController) Create(c echo.Context, actorID int) (domain.User, error) {
+	req := body.CreateUserRequest{}
+	if err := c.Bind(&req); err != nil {
+		return domain.User{}, err
+	}
+	return controller.Interactor.Add(actorID, domain.User{
+		Name: req.Name,
+		Role: req.Role,
+	})
+}
+
+func (controller *UserController) GetUser(actorID int) ([]domain.User, error) {
+	return controller.Interactor.GetInfo(actorID)
 }
 
-// 該当のLINEユーザーIDを持つユーザーが存在すればその情報を取得。存在しなければ作成したのちその情報を取得。
-func (controller *UserController) PrepareUser(lineUser body.LINEUser) domain.User {
-	user := controller.Interactor.Prepare(lineUser.UserID, lineUser.DisplayName)
-	return user
+func (controller *UserController) ChangeRole(c echo.Context, actorID int, id int) (domain.User, error) {
+	req := body.ChangeRoleRequest{}
+	if err := c.Bind(&req); err != nil {
+		return domain.User{}, err
+	}
+	return controller.Interactor.ChangeRole(actorID, id, req.Role)
+}
+
+func (controller *UserController) Delete(actorID int, id int) error {
+	return controller.Interactor.Delete(actorID, id)
 }
 
-func (controller *UserController) GetUser() []domain.User {
-	res := controller.Interactor.GetInfo()
-	return res
+func (controller *UserController) GetMe(userID int) (domain.User, error) {
+	return controller.Interactor.GetMe(userID)
 }
 
-func (controller *UserController) Delete(id int) {
-	controller.Interactor.Delete(id)
+// 送られてきた項目だけを更新する
+func (controller *UserController) UpdateMe(c echo.Context, userID int) (domain.User, error) {
+	req := body.UpdateMeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return domain.User{}, err
+	}
+	return controller.Interactor.UpdateMe(userID, usecase.ProfileUpdate{
+		Name:         req.Name,
+		Picture:      req.Picture,
+		Timezone:     req.Timezone,
+		ReadingSpeed: req.ReadingSpeed,
+	})
 }
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type AccountRepository struct {
+	SqlHandler
+}
+
+func (db *AccountRepository) Export(userID int) (domain.AccountExport, error) {
+	export := domain.AccountExport{
+		Identities:           []domain.Identity{},
+		Tsundokus:            []domain.Tsundoku{},
+		Tags:                 []domain.Tag{},
+		TsundokuTags:         []domain.TsundokuTag{},
+		ProgressLogs:         []domain.ProgressLog{},
+		ReadingSessions:      []domain.ReadingSession{},
+		PersonalAccessTokens: []domain.PersonalAccessToken{},
+	}
+	if err := db.FindObjByID(&export.User, userID); err != nil {
+		return domain.AccountExport{}, err
+	}
+	if err := db.FindAllUserItem(&export.Identities, userID); err != nil {
+		return domain.AccountExport{}, err
+	}
+	if err := db.FindAllUserItem(&export.PersonalAccessTokens, userID); err != nil {
+		return domain.AccountExport{}, err
+	}
+	if err := db.FindAllUserItem(&export.ProgressLogs, userID); err != nil {
+		return domain.AccountExport{}, err
+	}
+	if err := db.FindAllUserItem(&export.ReadingSessions, userID); err != nil {
+		return domain.AccountExport{}, err
+	}
+
+	// ゴミ箱に入っているものも書き出す
+	deletedTsundokus := []domain.Tsundoku{}
+	if err := db.FindAllUserItem(&export.Tsundokus, userID); err != nil {
+		return domain.AccountExport{}, err
+	}
+	if err := db.FindDeleted(&deletedTsundokus, "user_id = ?", userID); err != nil {
+		return domain.AccountExport{}, err
+	}
+	export.Tsundokus = append(export.Tsundokus, deletedTsundokus...)
+
+	deletedTags := []domain.Tag{}
+	if err := db.FindAllUserItem(&export.Tags, userID); err != nil {
+		return domain.AccountExport{}, err
+	}
+	if err := db.FindDeleted(&deletedTags, "user_id = ?", userID); err != nil {
+		return domain.AccountExport{}, err
+	}
+	export.Tags = append(export.Tags, deletedTags...)
+
+	deletedTsundokuTags := []domain.TsundokuTag{}
+	if err := db.FindAllUserItem(&export.TsundokuTags, userID); err != nil {
+		return domain.AccountExport{}, err
+	}
+	if err := db.FindDeleted(&deletedTsundokuTags, "user_id = ?", userID); err != nil {
+		return domain.AccountExport{}, err
+	}
+	export.TsundokuTags = append(export.TsundokuTags, deletedTsundokuTags...)
+
+	return export, nil
+}
+
+func (db *AccountRepository) ScheduleDeletion(userID int, at time.Time) error {
+	return db.Transaction(func(tx SqlHandler) error {
+		if err := tx.Exec("UPDATE users SET deletion_scheduled_at = ? WHERE id = ?", at, userID); err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM sessions WHERE user_id = ?", userID)
+	})
+}
+
+func (db *AccountRepository) PurgeScheduledBefore(before time.Time) error {
+	// 積読、タグ、セッションなどは外部キーのCASCADEで消える
+	return db.Exec("DELETE FROM users WHERE deletion_scheduled_at <= ?", before)
+}
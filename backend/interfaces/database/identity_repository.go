@@ -0,0 +1,59 @@
+package database
+
+import (
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type IdentityRepository struct {
+	SqlHandler
+}
+
+func (db *IdentityRepository) Store(identity domain.Identity) (domain.Identity, error) {
+	err := db.Create(&identity)
+	return identity, err
+}
+
+func (db *IdentityRepository) SelectByUser(userID int) ([]domain.Identity, error) {
+	identities := []domain.Identity{}
+	query := Query{Order: "id"}
+	query.Where("user_id = ?", userID)
+	err := db.FindByQuery(&identities, query)
+	return identities, err
+}
+
+func (db *IdentityRepository) SelectByID(id int) (domain.Identity, error) {
+	identity := domain.Identity{}
+	err := db.FindObjByID(&identity, id)
+	return identity, err
+}
+
+func (db *IdentityRepository) SelectBySubject(provider string, subject string) (domain.Identity, error) {
+	identities := []domain.Identity{}
+	query := Query{Limit: 1}
+	query.Where("provider = ? AND subject = ?", provider, subject)
+	if err := db.FindByQuery(&identities, query); err != nil {
+		return domain.Identity{}, err
+	}
+	if len(identities) == 0 {
+		return domain.Identity{}, domain.ErrNotFound
+	}
+	return identities[0], nil
+}
+
+func (db *IdentityRepository) DeleteUnlessLast(identity domain.Identity) error {
+	return db.Transaction(func(tx SqlHandler) error {
+		// 同時に外されても一つは残るように、ユーザーの行をロックしてから数える
+		users := []domain.User{}
+		if err := tx.Raw(&users, "SELECT * FROM users WHERE id = ? FOR UPDATE", identity.UserID); err != nil {
+			return err
+		}
+		row := struct{ Count int }{}
+		if err := tx.Raw(&row, "SELECT COUNT(*) AS count FROM identities WHERE user_id = ?", identity.UserID); err != nil {
+			return err
+		}
+		if row.Count <= 1 {
+			return domain.ConflictError("cannot unlink the last login identity")
+		}
+		return tx.Purge(&domain.Identity{}, "id = ? AND user_id = ?", identity.ID, identity.UserID)
+	})
+}
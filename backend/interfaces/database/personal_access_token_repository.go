@@ -0,0 +1,53 @@
+package database
+
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type PersonalAccessTokenRepository struct {
+	SqlHandler
+}
+
+func (db *PersonalAccessTokenRepository) Store(token domain.PersonalAccessToken) (domain.PersonalAccessToken, error) {
+	err := db.Create(&token)
+	return token, err
+}
+
+func (db *PersonalAccessTokenRepository) SelectByUser(userID int) ([]domain.PersonalAccessToken, error) {
+	tokens := []domain.PersonalAccessToken{}
+	query := Query{Order: "id"}
+	query.Where("user_id = ?", userID)
+	err := db.FindByQuery(&tokens, query)
+	return tokens, err
+}
+
+func (db *PersonalAccessTokenRepository) SelectByID(id int) (domain.PersonalAccessToken, error) {
+	token := domain.PersonalAccessToken{}
+	err := db.FindObjByID(&token, id)
+	return token, err
+}
+
+func (db *PersonalAccessTokenRepository) SelectByTokenHash(tokenHash string) (domain.PersonalAccessToken, error) {
+	tokens := []domain.PersonalAccessToken{}
+	query := Query{Limit: 1}
+	query.Where("token_hash = ?", tokenHash)
+	// 退会を予約しているユーザーのトークンは、ログインして取り消すまで使えない
+	query.Where("user_id IN (SELECT id FROM users WHERE deletion_scheduled_at IS NULL)")
+	if err := db.FindByQuery(&tokens, query); err != nil {
+		return domain.PersonalAccessToken{}, err
+	}
+	if len(tokens) == 0 {
+		return domain.PersonalAccessToken{}, domain.ErrNotFound
+	}
+	return tokens[0], nil
+}
+
+func (db *PersonalAccessTokenRepository) Touch(id int, usedAt time.Time, ip string) error {
+	return db.Exec("UPDATE personal_access_tokens SET last_used_at = ?, last_used_ip = ? WHERE id = ?", usedAt, ip, id)
+}
+
+func (db *PersonalAccessTokenRepository) DeleteByUser(userID, id int) error {
+	return db.Purge(&domain.PersonalAccessToken{}, "id = ? AND user_id = ?", id, userID)
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type ProgressRepository struct {
+	SqlHandler
+}
+
+func (db *ProgressRepository) Store(tsundoku domain.Tsundoku, log domain.ProgressLog) (domain.ProgressLog, error) {
+	err := db.Transaction(func(tx SqlHandler) error {
+		if err := tx.Create(&log); err != nil {
+			return err
+		}
+		return tx.Save(&tsundoku)
+	})
+	return log, err
+}
+
+func (db *ProgressRepository) SelectByTsundoku(tsundokuID int) ([]domain.ProgressLog, error) {
+	logs := []domain.ProgressLog{}
+	query := Query{Order: "created_at, id"}
+	query.Where("tsundoku_id = ?", tsundokuID)
+	err := db.FindByQuery(&logs, query)
+	return logs, err
+}
+
+func (db *ProgressRepository) SelectPace(userID int) (domain.ReadingPace, error) {
+	pace := domain.ReadingPace{}
+	// ページを戻した記録や時間の分からない記録は速さに含めない
+	err := db.Raw(&pace, `SELECT COALESCE(SUM(pages_read), 0) AS pages, COALESCE(SUM(minutes), 0) AS minutes
+		FROM progress_logs WHERE user_id = ? AND pages_read > 0 AND minutes > 0`, userID)
+	return pace, err
+}
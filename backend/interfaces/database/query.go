@@ -0,0 +1,35 @@
+package database
+
+import "github.com/yot-sailing/TSUNTSUN/usecase"
+
+// FindByQueryに渡す検索条件
+type Query struct {
+	Conditions []Condition // ANDでつなぐ
+	Order      string
+	Limit      int // 0なら制限しない
+}
+
+type Condition struct {
+	SQL  string
+	Args []interface{}
+}
+
+// 条件を追加する
+func (query *Query) Where(sql string, args ...interface{}) *Query {
+	query.Conditions = append(query.Conditions, Condition{SQL: sql, Args: args})
+	return query
+}
+
+// pageの並び順と範囲を追加する。sortExprは並び順の値のSQLの式で、同じ値の行はidで並べる
+func (query *Query) Page(sortExpr string, page usecase.Page) *Query {
+	direction, operator := "ASC", ">"
+	if page.Desc {
+		direction, operator = "DESC", "<"
+	}
+	if page.After != nil {
+		query.Where("("+sortExpr+", id) "+operator+" (?, ?)", page.After.Value, page.After.ID)
+	}
+	query.Order = sortExpr + " " + direction + ", id " + direction
+	query.Limit = page.Limit
+	return query
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+type ReadingSessionRepository struct {
+	SqlHandler
+}
+
+// タイマーが一人一つなのはreading_sessionsの部分一意インデックスで守る
+func (db *ReadingSessionRepository) Store(session domain.ReadingSession) (domain.ReadingSession, error) {
+	err := db.Create(&session)
+	return session, err
+}
+
+func (db *ReadingSessionRepository) SelectRunning(userID int) (domain.ReadingSession, error) {
+	sessions := []domain.ReadingSession{}
+	query := Query{Limit: 1}
+	query.Where("user_id = ? AND ended_at IS NULL", userID)
+	if err := db.FindByQuery(&sessions, query); err != nil {
+		return domain.ReadingSession{}, err
+	}
+	if len(sessions) == 0 {
+		return domain.ReadingSession{}, domain.ErrNotFound
+	}
+	return sessions[0], nil
+}
+
+func (db *ReadingSessionRepository) SelectByTsundoku(tsundokuID int) ([]domain.ReadingSession, error) {
+	sessions := []domain.ReadingSession{}
+	query := Query{Order: "started_at, id"}
+	query.Where("tsundoku_id = ?", tsundokuID)
+	err := db.FindByQuery(&sessions, query)
+	return sessions, err
+}
+
+func (db *ReadingSessionRepository) Update(session domain.ReadingSession) error {
+	return db.Save(&session)
+}
+
+func (db *ReadingSessionRepository) SelectSamples(userID int) ([]domain.ReadingSample, error) {
+	samples := []domain.ReadingSample{}
+	err := db.Raw(&samples, `SELECT t.id AS tsundoku_id, t.title, t.category, t.language, t.status, t.text_chars, t.text_words,
+			SUM(s.pages) AS pages, SUM(s.minutes) AS minutes,
+			(ARRAY_AGG(s.estimated_minutes ORDER BY s.started_at, s.id))[1] AS estimated_minutes
+		FROM reading_sessions s JOIN tsundokus t ON t.id = s.tsundoku_id
+		WHERE s.user_id = ? AND s.ended_at IS NOT NULL AND t.deleted_at IS NULL
+		GROUP BY t.id ORDER BY t.id`, userID)
+	return samples, err
+}
+
+// usecase.TextLength.Minutesと同じ計算をする
+func (db *ReadingSessionRepository) UpdateEstimates(userID int, speeds usecase.ReadingSpeeds) error {
+	return db.Exec(`UPDATE tsundokus SET required_minutes = GREATEST(1, ROUND(text_chars::numeric / ? + text_words::numeric / ?))
+		WHERE user_id = ? AND required_time_estimated AND (text_chars > 0 OR text_words > 0) AND status IN (?)`,
+		speeds.CharsPerMinute, speeds.WordsPerMinute, userID, domain.UnfinishedStatuses)
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
+
+// 日本語を分けられないPostgreSQLのパーサーを通さないように、
+// usecase.SearchTokensで分けた語彙をarray_to_tsvectorとtsqueryのリテラルでそのまま使う
+
+// 検索用のtsvectorを作るSQL。引数はSearchVectorArgs
+const SearchVectorSQL = `setweight(array_to_tsvector(string_to_array(?, ' ')), 'A')
+	|| setweight(array_to_tsvector(string_to_array(?, ' ')), 'B')
+	|| setweight(array_to_tsvector(string_to_array(?, ' ')), 'C')
+	|| setweight(array_to_tsvector(string_to_array(?, ' ')), 'D')`
+
+// URLにはほぼ必ず含まれるので検索の対象にしない
+var urlStopWords = map[string]bool{"http": true, "https": true, "www": true}
+
+// タイトル、著者、URL、メモの順に重みをつける
+func SearchVectorArgs(tsundoku domain.Tsundoku) []interface{} {
+	var urlTokens []string
+	for _, token := range usecase.SearchTokens(tsundoku.URL) {
+		if !urlStopWords[token] {
+			urlTokens = append(urlTokens, token)
+		}
+	}
+	return []interface{}{
+		strings.Join(usecase.SearchTokens(tsundoku.Title), " "),
+		strings.Join(usecase.SearchTokens(tsundoku.Author), " "),
+		strings.Join(urlTokens, " "),
+		strings.Join(usecase.SearchTokens(tsundoku.Note), " "),
+	}
+}
+
+// 検索語のトークンをすべて含むものにヒットするtsqueryのリテラル
+// 最後のトークンは入力途中かもしれないので、1文字のトークンはbigramの先頭として前方一致にする
+func searchQuery(tokens []string) string {
+	terms := make([]string, len(tokens))
+	for i, token := range tokens {
+		terms[i] = "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(token) + "'"
+		if i == len(tokens)-1 || len([]rune(token)) == 1 {
+			terms[i] += ":*"
+		}
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type SessionRepository struct {
+	SqlHandler
+}
+
+func (db *SessionRepository) Store(session domain.Session) (domain.Session, error) {
+	err := db.Create(&session)
+	return session, err
+}
+
+func (db *SessionRepository) SelectByTokenHash(tokenHash string) (domain.Session, error) {
+	sessions := []domain.Session{}
+	query := Query{Limit: 1}
+	query.Where("token_hash = ?", tokenHash)
+	if err := db.FindByQuery(&sessions, query); err != nil {
+		return domain.Session{}, err
+	}
+	if len(sessions) == 0 {
+		return domain.Session{}, domain.ErrNotFound
+	}
+	return sessions[0], nil
+}
+
+// oldを削除してsessionを作る。oldがすでに使われていたらdomain.ErrUnauthorized
+func (db *SessionRepository) Rotate(old domain.Session, session domain.Session) (domain.Session, error) {
+	err := db.Transaction(func(tx SqlHandler) error {
+		// 同時に同じトークンで取り替えても、削除できた方だけを成功させる
+		deleted := []domain.Session{}
+		if err := tx.Raw(&deleted, "DELETE FROM sessions WHERE id = ? RETURNING *", old.ID); err != nil {
+			return err
+		}
+		if len(deleted) == 0 {
+			return domain.UnauthorizedError("invalid refresh token")
+		}
+		return tx.Create(&session)
+	})
+	return session, err
+}
+
+func (db *SessionRepository) DeleteByTokenHash(tokenHash string) error {
+	return db.Purge(&domain.Session{}, "token_hash = ?", tokenHash)
+}
+
+func (db *SessionRepository) PurgeExpiredBefore(before time.Time) error {
+	return db.Purge(&domain.Session{}, "expires_at < ?", before)
+}
@@ -1,15 +1,29 @@
 package database
 
 import (
-	"github.com/yot-sailing/TSUNTSUN/domain"
+	"time"
 )
 
+// 返すエラーはすべてdomain.Error
 type SqlHandler interface {
-	Create(object interface{})
-	FindAll(object interface{})
-	DeleteById(object interface{}, id int)
-	FindAllUserItem(object interface{}, userID int)
-	FindObjByIDs(object interface{}, ids []int)
-	FindObjByMultiIDs(object interface{}, firstID int, secondID int)
-	FindOrCreateUser(user *domain.User, newUser *domain.User) int
+	Create(object interface{}) error
+	FindAll(object interface{}) error
+	FindObjByID(object interface{}, id int) error
+	Save(object interface{}) error
+	// objectの主キーの行のvaluesの列だけを更新する
+	Updates(object interface{}, values map[string]interface{}) error
+	DeleteById(object interface{}, id int) error
+	FindAllUserItem(object interface{}, userID int) error
+	FindAllUserItemIn(object interface{}, userID int, column string, values interface{}) error
+	FindObjByIDs(object interface{}, ids []int) error
+	FindObjByMultiIDs(object interface{}, firstID int, secondID int) error
+	FindByQuery(object interface{}, query Query) error
+	// ゴミ箱
+	FindDeleted(object interface{}, query string, args ...interface{}) error
+	SoftDelete(object interface{}, deletedAt time.Time, query string, args ...interface{}) (int64, error)
+	Restore(object interface{}, query string, args ...interface{}) (int64, error)
+	Purge(object interface{}, query string, args ...interface{}) error
+	Transaction(fn func(tx SqlHandler) error) error
+	Exec(sql string, args ...interface{}) error
+	Raw(object interface{}, sql string, args ...interface{}) error
 }
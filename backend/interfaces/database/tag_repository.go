@@ -1,23 +1,132 @@
 package database
 
-import "github.com/yot-sailing/TSUNTSUN/domain"
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
 
 type TagRepository struct {
 	SqlHandler
 }
 
-func (db *TagRepository) Store(tag domain.Tag) int {
-	db.Create(&tag)
-	return tag.ID
+// ユーザーのタグの条件
+const ownedTagQuery = "id = ? AND user_id = ?"
+
+func (db *TagRepository) Store(tag domain.Tag) (domain.Tag, error) {
+	err := db.Create(&tag)
+	return tag, err
 }
 
-func (db *TagRepository) Select(tagIDs []int) []domain.Tag {
+func (db *TagRepository) Select(tagIDs []int) ([]domain.Tag, error) {
 	tags := []domain.Tag{}
-	db.FindObjByIDs(&tags, tagIDs)
-	return tags
+	err := db.FindObjByIDs(&tags, tagIDs)
+	return tags, err
+}
+
+// タグには作成日時がないので、作成順はIDの順にする
+var tagSortExprs = map[string]string{
+	usecase.SortCreated: "id",
+	usecase.SortName:    "name",
 }
 
-func (db *TagRepository) Delete(id int) {
+func (db *TagRepository) SelectByUser(userID int, page usecase.Page) ([]domain.Tag, error) {
 	tags := []domain.Tag{}
-	db.DeleteById(&tags, id)
+	query := Query{}
+	query.Where("user_id = ?", userID)
+	query.Page(tagSortExprs[page.Sort], page)
+	err := db.FindByQuery(&tags, query)
+	return tags, err
+}
+
+func (db *TagRepository) SelectByID(id int) (domain.Tag, error) {
+	tag := domain.Tag{}
+	err := db.FindObjByID(&tag, id)
+	return tag, err
+}
+
+// ユーザーのタグを名前で取得
+func (db *TagRepository) SelectByName(userID int, name string) (domain.Tag, error) {
+	tags := []domain.Tag{}
+	if err := db.FindAllUserItemIn(&tags, userID, "name", []string{name}); err != nil {
+		return domain.Tag{}, err
+	}
+	if len(tags) == 0 {
+		return domain.Tag{}, domain.ErrNotFound
+	}
+	return tags[0], nil
+}
+
+func (db *TagRepository) Update(tag domain.Tag) error {
+	return db.Save(&tag)
+}
+
+// sourceIDのタグ付けをtargetIDに付け替えてからsourceIDのタグを削除する
+func (db *TagRepository) Merge(userID, sourceID, targetID int) error {
+	return db.Transaction(func(tx SqlHandler) error {
+		// 両方のタグがついている積読はsource側のタグ付けを消す
+		// 主キーが(tsundoku_id, tag_id)なので、ゴミ箱にあるtarget側のタグ付けとも重ならないようにする
+		err := tx.Exec(`DELETE FROM tsundoku_tags WHERE tag_id = ? AND user_id = ?
+			AND tsundoku_id IN (SELECT tsundoku_id FROM tsundoku_tags WHERE tag_id = ? AND user_id = ?)`, sourceID, userID, targetID, userID)
+		if err != nil {
+			return err
+		}
+		err = tx.Exec("UPDATE tsundoku_tags SET tag_id = ? WHERE tag_id = ? AND user_id = ?", targetID, sourceID, userID)
+		if err != nil {
+			return err
+		}
+		return tx.Purge(&domain.Tag{}, ownedTagQuery, sourceID, userID)
+	})
+}
+
+// ユーザーのタグとそのタグ付けを同じ時刻でゴミ箱に入れる
+func (db *TagRepository) DeleteByUser(userID, id int) error {
+	now := time.Now().Truncate(time.Microsecond)
+	return db.Transaction(func(tx SqlHandler) error {
+		affected, err := tx.SoftDelete(&domain.Tag{}, now, ownedTagQuery, id, userID)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return domain.ErrNotFound
+		}
+		_, err = tx.SoftDelete(&domain.TsundokuTag{}, now, "tag_id = ?", id)
+		return err
+	})
+}
+
+// ゴミ箱に入っているユーザーのタグを取得
+func (db *TagRepository) SelectDeleted(userID int) ([]domain.Tag, error) {
+	tags := []domain.Tag{}
+	err := db.FindDeleted(&tags, "user_id = ?", userID)
+	return tags, err
+}
+
+// ユーザーのタグと、タグと一緒にゴミ箱に入れたタグ付けを戻す
+func (db *TagRepository) RestoreByUser(userID, id int) error {
+	tags := []domain.Tag{}
+	if err := db.FindDeleted(&tags, ownedTagQuery, id, userID); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return domain.ErrNotFound
+	}
+	return db.Transaction(func(tx SqlHandler) error {
+		// 同じ名前のタグがすでにあればConflictになる
+		if _, err := tx.Restore(&domain.Tag{}, "id = ?", id); err != nil {
+			return err
+		}
+		_, err := tx.Restore(&domain.TsundokuTag{}, "tag_id = ? AND deleted_at = ?", id, *tags[0].DeletedAt)
+		return err
+	})
+}
+
+// タグ付けは外部キーのCASCADEで消える
+func (db *TagRepository) PurgeByUser(userID, id int) error {
+	return db.Purge(&domain.Tag{}, ownedTagQuery+" AND deleted_at IS NOT NULL", id, userID)
+}
+
+func (db *TagRepository) PurgeDeletedBefore(before time.Time) error {
+	return db.Purge(&domain.Tag{}, "deleted_at < ?", before)
 }
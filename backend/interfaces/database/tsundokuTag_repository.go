@@ -6,23 +6,49 @@ type TsundokuTagRepository struct {
 	SqlHandler
 }
 
-func (db *TsundokuTagRepository) Store(tsundokuTag domain.TsundokuTag) {
-	db.Create(&tsundokuTag)
+// すでに同じタグ付けがあればそれを使う
+func (db *TsundokuTagRepository) Store(tsundokuTag domain.TsundokuTag) error {
+	return db.Transaction(func(tx SqlHandler) error {
+		tsundokuTags, err := findTsundokuTag(tx, tsundokuTag.UserID, tsundokuTag.TsundokuID, tsundokuTag.TagID)
+		if err != nil || len(tsundokuTags) > 0 {
+			return err
+		}
+		return tx.Create(&tsundokuTag)
+	})
 }
 
-func (db *TsundokuTagRepository) Select(userID int) []domain.TsundokuTag {
+// ゴミ箱に入っていないタグ付けを探す
+func findTsundokuTag(tx SqlHandler, userID, tsundokuID, tagID int) ([]domain.TsundokuTag, error) {
 	tsundokuTags := []domain.TsundokuTag{}
-	db.FindAllUserItem(&tsundokuTags, userID)
-	return tsundokuTags
+	query := Query{Limit: 1}
+	query.Where("tsundoku_id = ? AND tag_id = ? AND user_id = ?", tsundokuID, tagID, userID)
+	err := tx.FindByQuery(&tsundokuTags, query)
+	return tsundokuTags, err
 }
 
-func (db *TsundokuTagRepository) SelectByMultiIDs(tsundokuID, userID int) []domain.TsundokuTag {
+func (db *TsundokuTagRepository) Select(userID int) ([]domain.TsundokuTag, error) {
 	tsundokuTags := []domain.TsundokuTag{}
-	db.FindObjByMultiIDs(&tsundokuTags, tsundokuID, userID)
-	return tsundokuTags
+	err := db.FindAllUserItem(&tsundokuTags, userID)
+	return tsundokuTags, err
 }
 
-func (db *TsundokuTagRepository) Delete(id int) {
+func (db *TsundokuTagRepository) SelectByMultiIDs(tsundokuID, userID int) ([]domain.TsundokuTag, error) {
 	tsundokuTags := []domain.TsundokuTag{}
-	db.DeleteById(&tsundokuTags, id)
+	err := db.FindObjByMultiIDs(&tsundokuTags, tsundokuID, userID)
+	return tsundokuTags, err
+}
+
+// タグ付けだけを完全に削除する。タグ自体や同じタグの他の積読へのタグ付けはそのまま
+// ゴミ箱に入れても戻す手段がないので、ゴミ箱には入れない
+func (db *TsundokuTagRepository) DeleteByUser(userID, tsundokuID, tagID int) error {
+	return db.Transaction(func(tx SqlHandler) error {
+		tsundokuTags, err := findTsundokuTag(tx, userID, tsundokuID, tagID)
+		if err != nil {
+			return err
+		}
+		if len(tsundokuTags) == 0 {
+			return domain.ErrNotFound
+		}
+		return tx.Purge(&domain.TsundokuTag{}, "tsundoku_id = ? AND tag_id = ? AND user_id = ?", tsundokuID, tagID, userID)
+	})
 }
@@ -1,22 +1,228 @@
 package database
 
-import "github.com/yot-sailing/TSUNTSUN/domain"
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/usecase"
+)
 
 type TsundokuRepository struct {
 	SqlHandler
 }
 
-func (db *TsundokuRepository) Store(tsundoku domain.Tsundoku) {
-	db.Create(&tsundoku)
+func (db *TsundokuRepository) Store(tsundoku domain.Tsundoku) (domain.Tsundoku, error) {
+	err := db.Transaction(func(tx SqlHandler) error {
+		if err := tx.Create(&tsundoku); err != nil {
+			return err
+		}
+		return updateSearchVector(tx, tsundoku)
+	})
+	return tsundoku, err
+}
+
+// 検索用のtsvectorを作り直す
+func updateSearchVector(handler SqlHandler, tsundoku domain.Tsundoku) error {
+	args := append(SearchVectorArgs(tsundoku), tsundoku.ID)
+	return handler.Exec("UPDATE tsundokus SET search_vector = "+SearchVectorSQL+" WHERE id = ?", args...)
+}
+
+func (db *TsundokuRepository) Select(userID int) ([]domain.Tsundoku, error) {
+	tsundokus := []domain.Tsundoku{}
+	err := db.FindAllUserItem(&tsundokus, userID)
+	return tsundokus, err
+}
+
+// 並び順の値の式。usecaseで作るカーソルの値と同じになるようにする
+var tsundokuSortExprs = map[string]string{
+	usecase.SortCreated: "created_at",
+	// 締め切りなしはゼロ値なので最後にする
+	usecase.SortDeadline:     "(CASE WHEN deadline > '0001-01-02' THEN deadline ELSE 'infinity' END)",
+	usecase.SortRequiredTime: "required_minutes",
+	usecase.SortTitle:        "title",
 }
 
-func (db *TsundokuRepository) Select(userID int) []domain.Tsundoku {
+func (db *TsundokuRepository) SelectByFilter(userID int, filter usecase.TsundokuFilter, page usecase.Page) ([]domain.Tsundoku, error) {
 	tsundokus := []domain.Tsundoku{}
-	db.FindAllUserItem(&tsundokus, userID)
-	return tsundokus
+	query := tsundokuFilterQuery(userID, filter)
+	query.Page(tsundokuSortExprs[page.Sort], page)
+	err := db.FindByQuery(&tsundokus, query)
+	return tsundokus, err
+}
+
+// 絞り込み条件をSQLの条件にする
+func tsundokuFilterQuery(userID int, filter usecase.TsundokuFilter) Query {
+	query := Query{}
+	query.Where("user_id = ?", userID)
+	if len(filter.Statuses) > 0 {
+		query.Where("status IN (?)", filter.Statuses)
+	}
+	if len(filter.Categories) > 0 {
+		query.Where("category IN (?)", filter.Categories)
+	}
+	if len(filter.Tags) > 0 {
+		taggedQuery := `id IN (SELECT tt.tsundoku_id FROM tsundoku_tags tt JOIN tags t ON t.id = tt.tag_id
+			WHERE tt.user_id = ? AND tt.deleted_at IS NULL AND t.deleted_at IS NULL AND t.name IN (?)`
+		if filter.TagMatch == usecase.TagMatchAll {
+			query.Where(taggedQuery+" GROUP BY tt.tsundoku_id HAVING COUNT(DISTINCT t.id) = ?)", userID, filter.Tags, len(filter.Tags))
+		} else {
+			query.Where(taggedQuery+")", userID, filter.Tags)
+		}
+	}
+	// 締め切りなしはゼロ値で保存されているので、締め切りでの絞り込みからは外す
+	if filter.DeadlineBefore != nil {
+		query.Where("deadline > ? AND deadline < ?", time.Time{}, *filter.DeadlineBefore)
+	}
+	if filter.DeadlineAfter != nil {
+		query.Where("deadline >= ?", *filter.DeadlineAfter)
+	}
+	if filter.HasURL != nil {
+		if *filter.HasURL {
+			query.Where("url <> ''")
+		} else {
+			query.Where("(url = '' OR url IS NULL)")
+		}
+	}
+	if filter.CreatedBefore != nil {
+		query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.CreatedAfter != nil {
+		query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	return query
+}
+
+// どの積読のタグかを一緒に読み込むための行
+type tsundokuTagRow struct {
+	TsundokuID int
+	domain.Tag
+}
+
+// 積読ごとのタグを積読の数によらず1回のクエリで取得する。タグのない積読はmapに含まれない
+func (db *TsundokuRepository) SelectTags(userID int, tsundokuIDs []int) (map[int][]domain.Tag, error) {
+	tags := map[int][]domain.Tag{}
+	if len(tsundokuIDs) == 0 {
+		return tags, nil
+	}
+	rows := []tsundokuTagRow{}
+	err := db.Raw(&rows, `SELECT tt.tsundoku_id, tags.* FROM tsundoku_tags tt JOIN tags ON tags.id = tt.tag_id
+		WHERE tt.user_id = ? AND tt.tsundoku_id IN (?) AND tt.deleted_at IS NULL AND tags.deleted_at IS NULL
+		ORDER BY tags.id`, userID, tsundokuIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		tags[row.TsundokuID] = append(tags[row.TsundokuID], row.Tag)
+	}
+	return tags, nil
+}
+
+func (db *TsundokuRepository) SelectByID(id int) (domain.Tsundoku, error) {
+	tsundoku := domain.Tsundoku{}
+	err := db.FindObjByID(&tsundoku, id)
+	return tsundoku, err
+}
+
+func (db *TsundokuRepository) Update(tsundoku domain.Tsundoku) error {
+	return db.Transaction(func(tx SqlHandler) error {
+		if err := tx.Save(&tsundoku); err != nil {
+			return err
+		}
+		return updateSearchVector(tx, tsundoku)
+	})
+}
+
+// ユーザーの積読を検索語のトークンで検索し、関連度の高い順に返す
+func (db *TsundokuRepository) Search(userID int, tokens []string, limit int) ([]domain.SearchResult, error) {
+	results := []domain.SearchResult{}
+	if len(tokens) == 0 {
+		return results, nil
+	}
+	err := db.Raw(&results, `SELECT tsundokus.*, ts_rank(search_vector, query) AS rank
+		FROM tsundokus, CAST(? AS tsquery) query
+		WHERE user_id = ? AND deleted_at IS NULL AND search_vector @@ query
+		ORDER BY rank DESC, id DESC LIMIT ?`, searchQuery(tokens), userID, limit)
+	return results, err
+}
+
+// ユーザーの積読とそのタグ付けを同じ時刻でゴミ箱に入れる
+func (db *TsundokuRepository) DeleteByUser(userID, id int) error {
+	now := time.Now().Truncate(time.Microsecond)
+	return db.Transaction(func(tx SqlHandler) error {
+		affected, err := tx.SoftDelete(&domain.Tsundoku{}, now, "id = ? AND user_id = ?", id, userID)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return domain.ErrNotFound
+		}
+		_, err = tx.SoftDelete(&domain.TsundokuTag{}, now, "tsundoku_id = ? AND user_id = ?", id, userID)
+		return err
+	})
+}
+
+// ゴミ箱に入っているユーザーの積読を取得
+func (db *TsundokuRepository) SelectDeleted(userID int) ([]domain.Tsundoku, error) {
+	tsundokus := []domain.Tsundoku{}
+	err := db.FindDeleted(&tsundokus, "user_id = ?", userID)
+	return tsundokus, err
+}
+
+func (db *TsundokuRepository) SelectDeletedByID(id int) (domain.Tsundoku, error) {
+	tsundokus := []domain.Tsundoku{}
+	if err := db.FindDeleted(&tsundokus, "id = ?", id); err != nil {
+		return domain.Tsundoku{}, err
+	}
+	if len(tsundokus) == 0 {
+		return domain.Tsundoku{}, domain.ErrNotFound
+	}
+	return tsundokus[0], nil
+}
+
+// ユーザーの積読と、積読と一緒にゴミ箱に入れたタグ付けを戻す
+func (db *TsundokuRepository) RestoreByUser(userID, id int) error {
+	tsundoku, err := db.SelectDeletedByID(id)
+	if err != nil {
+		return err
+	}
+	if tsundoku.UserID != userID {
+		return domain.ErrNotFound
+	}
+	return db.Transaction(func(tx SqlHandler) error {
+		if _, err := tx.Restore(&domain.Tsundoku{}, "id = ? AND user_id = ?", id, userID); err != nil {
+			return err
+		}
+		_, err := tx.Restore(&domain.TsundokuTag{}, "tsundoku_id = ? AND user_id = ? AND deleted_at = ?", id, userID, *tsundoku.DeletedAt)
+		return err
+	})
+}
+
+// タグ付けは外部キーのCASCADEで消える
+func (db *TsundokuRepository) PurgeByUser(userID, id int) error {
+	return db.Purge(&domain.Tsundoku{}, "id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID)
+}
+
+func (db *TsundokuRepository) PurgeDeletedBefore(before time.Time) error {
+	return db.Purge(&domain.Tsundoku{}, "deleted_at < ?", before)
+}
+
+func (db *TsundokuRepository) SelectPendingEstimates(maxAttempts int, retryBefore time.Time, limit int) ([]domain.Tsundoku, error) {
+	tsundokus := []domain.Tsundoku{}
+	query := Query{Order: "id", Limit: limit}
+	query.Where("category = ? AND url <> '' AND required_minutes = 0", domain.CategorySite)
+	query.Where("estimate_attempts < ?", maxAttempts)
+	query.Where("estimate_checked_at IS NULL OR estimate_checked_at < ?", retryBefore)
+	err := db.FindByQuery(&tsundokus, query)
+	return tsundokus, err
+}
+
+func (db *TsundokuRepository) StoreEstimate(tsundoku domain.Tsundoku, length usecase.TextLength, minutes int, checkedAt time.Time) error {
+	return db.Exec(`UPDATE tsundokus SET required_minutes = ?, required_time_estimated = TRUE, estimate_checked_at = ?,
+		text_chars = ?, text_words = ?, language = CASE WHEN language = '' THEN ? ELSE language END
+		WHERE id = ? AND url = ? AND (required_minutes = 0 OR required_time_estimated)`,
+		minutes, checkedAt, length.Chars, length.Words, length.Language(), tsundoku.ID, tsundoku.URL)
 }
 
-func (db *TsundokuRepository) Delete(id int) {
-	tsundoku := []domain.Tsundoku{}
-	db.DeleteById(&tsundoku, id)
+func (db *TsundokuRepository) RecordEstimateFailure(id int, attempts int, checkedAt time.Time) error {
+	return db.Exec("UPDATE tsundokus SET estimate_attempts = ?, estimate_checked_at = ? WHERE id = ?", attempts, checkedAt, id)
 }
@@ -1,6 +1,9 @@
 package database
 
 import (
+	"errors"
+	"time"
+
 	"github.com/yot-sailing/TSUNTSUN/domain"
 )
 
@@ -8,30 +11,92 @@ type UserRepository struct {
 	SqlHandler
 }
 
-func (db *UserRepository) Store(u domain.User) {
-	db.Create(&u)
+func (db *UserRepository) Store(u domain.User) (domain.User, error) {
+	err := db.Create(&u)
+	return u, err
 }
 
-func (db *UserRepository) Select() []domain.User {
+func (db *UserRepository) Select() ([]domain.User, error) {
 	users := []domain.User{}
-	db.FindAll(&users)
-	return users
+	err := db.FindAll(&users)
+	return users, err
 }
 
-func (db *UserRepository) Prepare(userID string, userName string) domain.User {
+func (db *UserRepository) SelectByID(id int) (domain.User, error) {
 	user := domain.User{}
-	newUser := domain.User{
-		Name:   userName,
-		LINEID: userID,
+	err := db.FindObjByID(&user, id)
+	return user, err
+}
+
+func (db *UserRepository) Prepare(identity domain.Identity, newUser domain.User) (domain.User, error) {
+	user, err := db.prepare(identity, newUser)
+	// 同時に初めてログインしたときは、先に作られたユーザーを使う
+	if errors.Is(err, domain.ErrConflict) {
+		user, err = db.prepare(identity, newUser)
 	}
-	affect := db.FindOrCreateUser(&user, &newUser)
-	if affect == 0 {
-		return newUser
+	return user, err
+}
+
+func (db *UserRepository) prepare(identity domain.Identity, newUser domain.User) (domain.User, error) {
+	user := domain.User{}
+	err := db.Transaction(func(tx SqlHandler) error {
+		identities := []domain.Identity{}
+		query := Query{Limit: 1}
+		query.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject)
+		if err := tx.FindByQuery(&identities, query); err != nil {
+			return err
+		}
+		if len(identities) == 0 {
+			user = newUser
+			if err := tx.Create(&user); err != nil {
+				return err
+			}
+			identity.UserID = user.ID
+			return tx.Create(&identity)
+		}
+
+		if err := tx.FindObjByID(&user, identities[0].UserID); err != nil {
+			return err
+		}
+		if user.SyncProfile(newUser) {
+			return tx.Save(&user)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.User{}, err
 	}
-	return user
+	return user, nil
+}
+
+// プロフィールの列だけを更新する。ログインや退会の予約で同時に変わる列は上書きしない
+func (db *UserRepository) UpdateProfile(user domain.User) (domain.User, error) {
+	err := db.Updates(&user, map[string]interface{}{
+		"name":               user.Name,
+		"name_overridden":    user.NameOverridden,
+		"picture":            user.Picture,
+		"picture_overridden": user.PictureOverridden,
+		"timezone":           user.Timezone,
+		"reading_speed":      user.ReadingSpeed,
+	})
+	return user, err
+}
+
+func (db *UserRepository) UpdateRole(id int, role string) error {
+	return db.Exec("UPDATE users SET role = ?, updated_at = ? WHERE id = ?", role, time.Now(), id)
+}
+
+func (db *UserRepository) CancelDeletion(id int) error {
+	return db.Exec("UPDATE users SET deletion_scheduled_at = NULL, updated_at = ? WHERE id = ?", time.Now(), id)
+}
+
+func (db *UserRepository) CountByRole(role string) (int, error) {
+	row := struct{ Count int }{}
+	err := db.Raw(&row, "SELECT COUNT(*) AS count FROM users WHERE role = ?", role)
+	return row.Count, err
 }
 
-func (db *UserRepository) Delete(id int) {
+func (db *UserRepository) Delete(id int) error {
 	user := []domain.User{}
-	db.DeleteById(&user, id)
+	return db.DeleteById(&user, id)
 }
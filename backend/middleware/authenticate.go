@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/controllers"
+)
+
+// 認証した利用者と許可された操作を入れておくコンテキストのキー
+const (
+	userContextKey   = "user"
+	scopesContextKey = "scopes"
+)
+
+// アクセストークンか個人用アクセストークンを検証して、利用者をコンテキストに入れる。検証できなければ401を返し、ハンドラーは呼ばない
+// 個人用アクセストークンでは、GETにはread、それ以外にはwriteのスコープが必要
+// 個人用アクセストークンを使ったIPアドレスはClientIPで調べる
+func Authenticate(sessionController *controllers.SessionController, tokenController *controllers.PersonalAccessTokenController, trustedProxies []*net.IPNet) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+			var user domain.User
+			// ログインで発行したトークンはすべての操作ができる
+			scopes := domain.Scopes{domain.ScopeAdmin}
+			if controllers.IsPersonalAccessToken(authorization) {
+				token, err := tokenController.Authenticate(authorization, ClientIP(c.Request(), trustedProxies))
+				if err != nil {
+					return err
+				}
+				user, scopes = domain.User{ID: token.UserID}, token.Scopes
+			} else {
+				var err error
+				if user, err = AuthUser(authorization, sessionController); err != nil {
+					return err
+				}
+			}
+
+			required := domain.ScopeWrite
+			if method := c.Request().Method; method == http.MethodGet || method == http.MethodHead {
+				required = domain.ScopeRead
+			}
+			if !scopes.Allows(required) {
+				return domain.ForbiddenError("token does not have the " + required + " scope")
+			}
+
+			c.Set(userContextKey, user)
+			c.Set(scopesContextKey, scopes)
+			return next(c)
+		}
+	}
+}
+
+// Authenticateのあとに使い、scopeのないトークンでのリクエストを403にする
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			scopes, _ := c.Get(scopesContextKey).(domain.Scopes)
+			if !scopes.Allows(scope) {
+				return domain.ForbiddenError("token does not have the " + scope + " scope")
+			}
+			return next(c)
+		}
+	}
+}
+
+// Authenticateを通ったハンドラーで利用者を取得する
+func CurrentUser(c echo.Context) domain.User {
+	user, _ := c.Get(userContextKey).(domain.User)
+	return user
+}
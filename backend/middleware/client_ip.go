@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// リクエストを送ってきたクライアントのIPアドレス
+// X-Forwarded-Forは誰でも付けられるので、接続元がtrustedProxiesのどれかのときだけ使う
+// そのときは右から見て、信頼するプロキシでない最初のアドレスを返す
+func ClientIP(req *http.Request, trustedProxies []*net.IPNet) string {
+	remote, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		remote = req.RemoteAddr
+	}
+	if !isTrustedProxy(remote, trustedProxies) {
+		return remote
+	}
+	var forwarded []string
+	for _, header := range req.Header[echo.HeaderXForwardedFor] {
+		for _, ip := range strings.Split(header, ",") {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				forwarded = append(forwarded, ip)
+			}
+		}
+	}
+	ip := remote
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip = forwarded[i]
+		if !isTrustedProxy(ip, trustedProxies) {
+			break
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(ip string, trustedProxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted := []*net.IPNet{proxies}
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		forwardedFor   []string
+		trustedProxies []*net.IPNet
+		want           string
+	}{
+		{"no proxy", "203.0.113.1:5000", nil, nil, "203.0.113.1"},
+		{"forwarded for without trusted proxies", "203.0.113.1:5000", []string{"198.51.100.1"}, nil, "203.0.113.1"},
+		{"forwarded for from untrusted address", "203.0.113.1:5000", []string{"198.51.100.1"}, trusted, "203.0.113.1"},
+		{"trusted proxy", "10.0.0.1:5000", []string{"198.51.100.1"}, trusted, "198.51.100.1"},
+		// クライアントが付けた値は左にあるので、プロキシが付けた右端を使う
+		{"spoofed forwarded for", "10.0.0.1:5000", []string{"192.0.2.1, 198.51.100.1"}, trusted, "198.51.100.1"},
+		{"chained proxies", "10.0.0.1:5000", []string{"198.51.100.1, 10.0.0.2", "10.0.0.3"}, trusted, "198.51.100.1"},
+		{"only proxies", "10.0.0.1:5000", []string{"10.0.0.2"}, trusted, "10.0.0.2"},
+		{"trusted proxy without header", "10.0.0.1:5000", nil, trusted, "10.0.0.1"},
+		{"ipv6", "[2001:db8::1]:5000", nil, nil, "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, header := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", header)
+			}
+			if got := ClientIP(req, tt.trustedProxies); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
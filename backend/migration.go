@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"github.com/yot-sailing/TSUNTSUN/domain"
+	"github.com/yot-sailing/TSUNTSUN/interfaces/database"
+)
+
+// タグをユーザーごとに持つようにする前のデータを移行する。何度実行しても同じ結果になる
+func migrateTagOwnership(db *gorm.DB) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			// タグ付けからタグの持ち主を決める
+			`UPDATE tags SET user_id = tt.user_id
+			FROM (SELECT tag_id, MIN(user_id) AS user_id FROM tsundoku_tags GROUP BY tag_id) tt
+			WHERE tags.id = tt.tag_id AND tags.user_id IS NULL`,
+			// 複数のユーザーが使っているタグは、持ち主以外のユーザー用に複製する
+			`INSERT INTO tags (name, user_id)
+			SELECT DISTINCT t.name, tt.user_id FROM tags t JOIN tsundoku_tags tt ON tt.tag_id = t.id
+			WHERE tt.user_id <> t.user_id`,
+			`UPDATE tsundoku_tags tt SET tag_id = (
+				SELECT MIN(c.id) FROM tags c WHERE c.user_id = tt.user_id AND c.name = t.name
+			)
+			FROM tags t WHERE tt.tag_id = t.id AND tt.user_id <> t.user_id`,
+			// どの積読にもついていないタグは持ち主が分からないので消す
+			`DELETE FROM tags WHERE user_id IS NULL`,
+			// ユーザーごとに同じ名前のタグを一番古いものにまとめる
+			`CREATE TEMP TABLE tag_canon ON COMMIT DROP AS
+			SELECT id, MIN(id) OVER (PARTITION BY user_id, name) AS keep_id
+			FROM tags WHERE deleted_at IS NULL`,
+			// まとめると同じ積読に同じタグが重複するものは一つだけ残す
+			`DELETE FROM tsundoku_tags tt USING (
+				SELECT tt.tsundoku_id, tt.tag_id, ROW_NUMBER() OVER (
+					PARTITION BY tt.tsundoku_id, c.keep_id ORDER BY (tt.tag_id = c.keep_id) DESC, tt.tag_id
+				) AS rn
+				FROM tsundoku_tags tt JOIN tag_canon c ON c.id = tt.tag_id
+			) d
+			WHERE tt.tsundoku_id = d.tsundoku_id AND tt.tag_id = d.tag_id AND d.rn > 1`,
+			`UPDATE tsundoku_tags tt SET tag_id = c.keep_id FROM tag_canon c
+			WHERE tt.tag_id = c.id AND c.id <> c.keep_id`,
+			`DELETE FROM tags t USING tag_canon c WHERE t.id = c.id AND c.id <> c.keep_id`,
+			// ゴミ箱に入っているものは同じ名前でもよい
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_id_name ON tags (user_id, name) WHERE deleted_at IS NULL`,
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+	db.Model(domain.Tag{}).AddForeignKey("user_id", "users(id)", "CASCADE", "CASCADE")
+}
+
+// 全文検索用の列とインデックスを作り、まだ作っていない積読のtsvectorを作る
+func migrateSearchVector(db *gorm.DB) {
+	db.Exec("ALTER TABLE tsundokus ADD COLUMN IF NOT EXISTS search_vector tsvector")
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_tsundokus_search_vector ON tsundokus USING GIN (search_vector)")
+
+	tsundokus := []domain.Tsundoku{}
+	if err := db.Unscoped().Where("search_vector IS NULL").Find(&tsundokus).Error; err != nil {
+		panic(err.Error())
+	}
+	for _, tsundoku := range tsundokus {
+		args := append(database.SearchVectorArgs(tsundoku), tsundoku.ID)
+		if err := db.Exec("UPDATE tsundokus SET search_vector = "+database.SearchVectorSQL+" WHERE id = ?", args...).Error; err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+// usersのline_idに入っていたIDプロバイダーのユーザーIDをidentitiesに移す
+// line_idにはそのときIDENTITY_PROVIDERで選んでいたプロバイダーのIDが入っている
+// 移したline_idは空にするので、紐づけを外したあとに再起動しても元に戻らない
+func migrateIdentities(db *gorm.DB) {
+	if !db.Dialect().HasColumn("users", "line_id") {
+		return
+	}
+	provider := strings.TrimSpace(strings.Split(os.Getenv("IDENTITY_PROVIDER"), ",")[0])
+	if provider == "" {
+		provider = "line"
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO identities (user_id, provider, subject, created_at)
+			SELECT id, ?, line_id, created_at FROM users WHERE line_id IS NOT NULL AND line_id <> ''
+			ON CONFLICT DO NOTHING`, provider).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE users SET line_id = NULL WHERE line_id IS NOT NULL").Error
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+}
+
+// 文字列のrequired_timeを分に変換してrequired_minutesに移す
+// 読めなかったものは不明(0)にする。移したrequired_timeは空にするので何度実行してもよい
+func migrateRequiredTime(db *gorm.DB) {
+	if !db.Dialect().HasColumn("tsundokus", "required_time") {
+		return
+	}
+	rows := []struct {
+		ID           int
+		RequiredTime string
+	}{}
+	err := db.Raw("SELECT id, required_time FROM tsundokus WHERE required_time IS NOT NULL AND required_time <> ''").Scan(&rows).Error
+	if err != nil {
+		panic(err.Error())
+	}
+	for _, row := range rows {
+		// 読めなければminutesは0になる
+		minutes, _ := domain.ParseRequiredTime(row.RequiredTime)
+		if err := db.Exec("UPDATE tsundokus SET required_minutes = ?, required_time = NULL WHERE id = ?", minutes, row.ID).Error; err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+// タイマーを動かせるのは一人一つ。止めていない読書を同時に二つ作れないようにする
+func migrateReadingSessions(db *gorm.DB) {
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_reading_sessions_running ON reading_sessions (user_id) WHERE ended_at IS NULL")
+}
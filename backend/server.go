@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"os"
+	// タイムゾーンのデータベースが無い環境でもユーザーのタイムゾーンを読めるようにする
+	_ "time/tzdata"
 
 	"github.com/jinzhu/gorm"
 	"github.com/joho/godotenv"
@@ -36,5 +38,15 @@ func dbinit() {
 	db.AutoMigrate(domain.Tsundoku{}).AddForeignKey("user_id", "users(id)", "CASCADE", "CASCADE")
 	db.AutoMigrate(domain.Tag{})
 	db.AutoMigrate(domain.TsundokuTag{}).AddForeignKey("tsundoku_id", "tsundokus(id)", "CASCADE", "CASCADE").AddForeignKey("tag_id", "tags(id)", "CASCADE", "CASCADE").AddForeignKey("user_id", "users(id)", "CASCADE", "CASCADE")
+	db.AutoMigrate(domain.Session{}).AddForeignKey("user_id", "users(id)", "CASCADE", "CASCADE")
+	db.AutoMigrate(domain.PersonalAccessToken{}).AddForeignKey("user_id", "users(id)", "CASCADE", "CASCADE")
+	db.AutoMigrate(domain.Identity{}).AddForeignKey("user_id", "users(id)", "CASCADE", "CASCADE")
+	db.AutoMigrate(domain.ProgressLog{}).AddForeignKey("tsundoku_id", "tsundokus(id)", "CASCADE", "CASCADE").AddForeignKey("user_id", "users(id)", "CASCADE", "CASCADE")
+	db.AutoMigrate(domain.ReadingSession{}).AddForeignKey("tsundoku_id", "tsundokus(id)", "CASCADE", "CASCADE").AddForeignKey("user_id", "users(id)", "CASCADE", "CASCADE")
+	migrateTagOwnership(db)
+	migrateSearchVector(db)
+	migrateIdentities(db)
+	migrateRequiredTime(db)
+	migrateReadingSessions(db)
 	fmt.Println("db connected: ", &db)
 }
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// 退会を予約してから削除するまでの期間のデフォルト
+const DefaultAccountDeletionGracePeriod = 14 * 24 * time.Hour
+
+type AccountInteractor struct {
+	AccountRepository AccountRepository
+	IdentityProviders IdentityProviders
+	GracePeriod       time.Duration
+}
+
+func (interactor *AccountInteractor) Export(userID int) (domain.AccountExport, error) {
+	export, err := interactor.AccountRepository.Export(userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.AccountExport{}, domain.UnauthorizedError("user no longer exists")
+	}
+	if err != nil {
+		return domain.AccountExport{}, err
+	}
+	export.ExportedAt = time.Now()
+	return export, nil
+}
+
+// 退会を予約する。先にすべてのデータを書き出して返し、IDプロバイダーのアクセストークンを無効にする
+// 猶予期間のうちにログインすれば取り消される
+// 個人用アクセストークンは取り消したときにまた使えるように残し、削除するときにユーザーと一緒に消す
+func (interactor *AccountInteractor) ScheduleDeletion(userID int, provider string, accessToken string) (domain.AccountExport, error) {
+	if accessToken == "" {
+		return domain.AccountExport{}, domain.ValidationError("accessToken of the identity provider is required")
+	}
+	identityProvider, err := interactor.IdentityProviders.Get(provider)
+	if err != nil {
+		return domain.AccountExport{}, err
+	}
+	export, err := interactor.Export(userID)
+	if err != nil {
+		return domain.AccountExport{}, err
+	}
+
+	// すでに無効なトークンなら、そのまま退会を進める
+	if err := identityProvider.Revoke(accessToken); err != nil && !errors.Is(err, domain.ErrUnauthorized) {
+		return domain.AccountExport{}, err
+	}
+
+	at := export.ExportedAt.Add(interactor.gracePeriod())
+	if err := interactor.AccountRepository.ScheduleDeletion(userID, at); err != nil {
+		return domain.AccountExport{}, err
+	}
+	export.User.DeletionScheduledAt = &at
+	return export, nil
+}
+
+// 猶予期間が過ぎたユーザーを削除する
+func (interactor *AccountInteractor) PurgeScheduled() error {
+	return interactor.AccountRepository.PurgeScheduledBefore(time.Now())
+}
+
+func (interactor *AccountInteractor) gracePeriod() time.Duration {
+	if interactor.GracePeriod <= 0 {
+		return DefaultAccountDeletionGracePeriod
+	}
+	return interactor.GracePeriod
+}
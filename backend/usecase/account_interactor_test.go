@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type memoryAccountRepository struct {
+	user        domain.User
+	scheduledAt *time.Time
+}
+
+func (db *memoryAccountRepository) Export(userID int) (domain.AccountExport, error) {
+	if db.user.ID != userID {
+		return domain.AccountExport{}, domain.ErrNotFound
+	}
+	return domain.AccountExport{User: db.user}, nil
+}
+
+func (db *memoryAccountRepository) ScheduleDeletion(userID int, at time.Time) error {
+	db.scheduledAt = &at
+	return nil
+}
+
+func (db *memoryAccountRepository) PurgeScheduledBefore(before time.Time) error {
+	return nil
+}
+
+// 無効にしたアクセストークンを覚えておくIDプロバイダー
+type revokingProvider struct {
+	revoked []string
+}
+
+func (provider *revokingProvider) Name() string { return "line" }
+
+func (provider *revokingProvider) VerifyAccessToken(accessToken string) error { return nil }
+
+func (provider *revokingProvider) Profile(accessToken string) (ProviderProfile, error) {
+	return ProviderProfile{}, nil
+}
+
+func (provider *revokingProvider) VerifyIDToken(idToken string, nonce string) (ProviderProfile, error) {
+	return ProviderProfile{}, nil
+}
+
+func (provider *revokingProvider) Revoke(accessToken string) error {
+	provider.revoked = append(provider.revoked, accessToken)
+	return nil
+}
+
+func TestScheduleDeletion(t *testing.T) {
+	t.Run("revokes the provider token", func(t *testing.T) {
+		repository := &memoryAccountRepository{user: domain.User{ID: alice}}
+		provider := &revokingProvider{}
+		interactor := AccountInteractor{AccountRepository: repository, IdentityProviders: IdentityProviders{provider}, GracePeriod: time.Hour}
+		export, err := interactor.ScheduleDeletion(alice, "line", "token")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(provider.revoked) != 1 || provider.revoked[0] != "token" {
+			t.Errorf("revoked %v, want [token]", provider.revoked)
+		}
+		if repository.scheduledAt == nil || export.User.DeletionScheduledAt == nil || !repository.scheduledAt.Equal(*export.User.DeletionScheduledAt) {
+			t.Errorf("deletion was not scheduled at the exported time")
+		}
+	})
+
+	t.Run("access token is required", func(t *testing.T) {
+		repository := &memoryAccountRepository{user: domain.User{ID: alice}}
+		interactor := AccountInteractor{AccountRepository: repository, IdentityProviders: IdentityProviders{&revokingProvider{}}}
+		if _, err := interactor.ScheduleDeletion(alice, "line", ""); !errors.Is(err, domain.ErrValidation) {
+			t.Errorf("got error %v, want %v", err, domain.ErrValidation)
+		}
+		if repository.scheduledAt != nil {
+			t.Errorf("deletion was scheduled without revoking the provider token")
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type AccountRepository interface {
+	// ユーザーのデータをゴミ箱の中も含めてすべて取得
+	Export(userID int) (domain.AccountExport, error)
+	// 削除する日時を記録して、ログインし直さないと使えないようにセッションを消す
+	// 個人用アクセストークンは残すが、予約を取り消すまで使えない
+	ScheduleDeletion(userID int, at time.Time) error
+	// 削除する日時を過ぎたユーザーを、積読やタグごと削除する
+	PurgeScheduledBefore(before time.Time) error
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// 利用者がその操作をしてよいかを判断する
+// 役割はトークンに入れず、毎回データベースから読むので変更がすぐ反映される
+type Authorizer struct {
+	UserRepository UserRepository
+}
+
+// 操作している利用者を取得する。退会などでいなければdomain.ErrUnauthorized
+func (authorizer *Authorizer) Actor(userID int) (domain.User, error) {
+	user, err := authorizer.UserRepository.SelectByID(userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.User{}, domain.UnauthorizedError("user no longer exists")
+	}
+	return user, err
+}
+
+// 管理者でなければdomain.ErrForbidden
+func (authorizer *Authorizer) RequireAdmin(userID int) (domain.User, error) {
+	actor, err := authorizer.Actor(userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if !actor.IsAdmin() {
+		return domain.User{}, domain.ForbiddenError("admin role is required")
+	}
+	return actor, nil
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type IdentityInteractor struct {
+	IdentityProviders  IdentityProviders
+	IdentityRepository IdentityRepository
+}
+
+func (interactor *IdentityInteractor) GetInfoByUser(userID int) ([]domain.Identity, error) {
+	return interactor.IdentityRepository.SelectByUser(userID)
+}
+
+// ログイン中のユーザーに別のIDプロバイダーのアカウントを紐づける
+// 紐づけ済みならそのまま返す。他のユーザーに紐づいているならdomain.ErrConflict
+func (interactor *IdentityInteractor) Link(userID int, credential ProviderCredential) (domain.Identity, error) {
+	provider, profile, err := interactor.IdentityProviders.Verify(credential)
+	if err != nil {
+		return domain.Identity{}, err
+	}
+
+	identity, err := interactor.IdentityRepository.SelectBySubject(provider, profile.Subject)
+	if err == nil {
+		if identity.UserID != userID {
+			return domain.Identity{}, domain.ConflictError("identity is linked to another account")
+		}
+		return identity, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return domain.Identity{}, err
+	}
+	return interactor.IdentityRepository.Store(domain.Identity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  profile.Subject,
+	})
+}
+
+// 紐づけを外す。ログインできなくならないように、最後の一つは外せない
+func (interactor *IdentityInteractor) Unlink(userID int, id int) error {
+	identity, err := interactor.IdentityRepository.SelectByID(id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NotFoundError("identity not found")
+	}
+	if err != nil {
+		return err
+	}
+	if identity.UserID != userID {
+		return domain.ForbiddenError("identity is not yours")
+	}
+	return interactor.IdentityRepository.DeleteUnlessLast(identity)
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// 外部のIDプロバイダーで確認できた利用者の情報
+type ProviderProfile struct {
+	Subject string // プロバイダー内で一意なID
+	Name    string
+	Picture string
+	Email   string
+	// LINEのステータスメッセージ
+	StatusMessage string
+}
+
+// LINEなどのIDプロバイダー。アクセストークンはプロバイダーが発行したもの
+type IdentityProvider interface {
+	// プロバイダーの名前(line、oidcなど)
+	Name() string
+	// アクセストークンが有効か確認する。無効ならdomain.ErrUnauthorized
+	VerifyAccessToken(accessToken string) error
+	Profile(accessToken string) (ProviderProfile, error)
+	// IDトークンの署名と内容を確かめて、含まれている利用者の情報を返す。nonceはログインを始めたクライアントが作った値
+	VerifyIDToken(idToken string, nonce string) (ProviderProfile, error)
+	// アクセストークンを無効にする
+	Revoke(accessToken string) error
+}
+
+// IDプロバイダーで本人確認するための値。IDトークンとnonceか、アクセストークンのどちらかを使う
+type ProviderCredential struct {
+	Provider    string // 空ならIdentityProvidersの最初のプロバイダー
+	AccessToken string
+	IDToken     string
+	Nonce       string
+}
+
+// 使えるIDプロバイダー。最初のものがデフォルト
+type IdentityProviders []IdentityProvider
+
+// 名前でプロバイダーを選ぶ。空なら最初のもの
+func (providers IdentityProviders) Get(name string) (IdentityProvider, error) {
+	if len(providers) == 0 {
+		return nil, domain.InternalError(errors.New("no identity provider is configured"))
+	}
+	if name == "" {
+		return providers[0], nil
+	}
+	for _, provider := range providers {
+		if provider.Name() == name {
+			return provider, nil
+		}
+	}
+	return nil, domain.ValidationError("unknown provider: " + name)
+}
+
+// 本人確認して、プロバイダーの名前と利用者の情報を返す
+func (providers IdentityProviders) Verify(credential ProviderCredential) (string, ProviderProfile, error) {
+	provider, err := providers.Get(credential.Provider)
+	if err != nil {
+		return "", ProviderProfile{}, err
+	}
+
+	var profile ProviderProfile
+	switch {
+	case credential.IDToken != "":
+		if credential.Nonce == "" {
+			return "", ProviderProfile{}, domain.ValidationError("nonce is required")
+		}
+		profile, err = provider.VerifyIDToken(credential.IDToken, credential.Nonce)
+	case credential.AccessToken != "":
+		if err = provider.VerifyAccessToken(credential.AccessToken); err == nil {
+			profile, err = provider.Profile(credential.AccessToken)
+		}
+	default:
+		return "", ProviderProfile{}, domain.UnauthorizedError("access token or id token is required")
+	}
+	if err != nil {
+		return "", ProviderProfile{}, err
+	}
+	if profile.Subject == "" {
+		return "", ProviderProfile{}, domain.UnauthorizedError("identity provider returned no subject")
+	}
+	return provider.Name(), profile, nil
+}
@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type IdentityRepository interface {
+	Store(identity domain.Identity) (domain.Identity, error)
+	SelectByUser(userID int) ([]domain.Identity, error)
+	SelectByID(id int) (domain.Identity, error)
+	// 無ければdomain.ErrNotFound
+	SelectBySubject(provider string, subject string) (domain.Identity, error)
+	// ユーザーに他のIDが残るときだけ削除する。最後の一つならdomain.ErrConflict
+	DeleteUnlessLast(identity domain.Identity) error
+}
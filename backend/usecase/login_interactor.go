@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type LoginInteractor struct {
+	IdentityProviders IdentityProviders
+	UserRepository    UserRepository
+	// ログインしたら管理者にするIDプロバイダーとそのユーザーID。最初の管理者を作るために使う
+	AdminSubjects []domain.Identity
+}
+
+// IDプロバイダーで本人確認して、紐づいているユーザーを返す。初めてのときはユーザーを作成する
+// 退会を予約していたら取り消す
+func (interactor *LoginInteractor) Login(credential ProviderCredential) (domain.User, error) {
+	provider, profile, err := interactor.IdentityProviders.Verify(credential)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	user, err := interactor.UserRepository.Prepare(domain.Identity{
+		Provider: provider,
+		Subject:  profile.Subject,
+	}, domain.User{
+		Name:          profile.Name,
+		Email:         profile.Email,
+		Picture:       profile.Picture,
+		StatusMessage: profile.StatusMessage,
+		Timezone:      domain.DefaultTimezone,
+		Role:          domain.RoleUser,
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	// 退会を予約していても、猶予期間のうちにログインすれば取り消す
+	if user.DeletionScheduledAt != nil {
+		if err := interactor.UserRepository.CancelDeletion(user.ID); err != nil {
+			return domain.User{}, err
+		}
+		user.DeletionScheduledAt = nil
+	}
+	if !user.IsAdmin() && interactor.isAdminSubject(provider, profile.Subject) {
+		if err := interactor.UserRepository.UpdateRole(user.ID, domain.RoleAdmin); err != nil {
+			return domain.User{}, err
+		}
+		user.Role = domain.RoleAdmin
+	}
+	return user, nil
+}
+
+// ユーザーIDはプロバイダーごとに振られるので、別のプロバイダーの同じIDは管理者にしない
+func (interactor *LoginInteractor) isAdminSubject(provider string, subject string) bool {
+	for _, s := range interactor.AdminSubjects {
+		if s.Provider == provider && s.Subject == subject {
+			return true
+		}
+	}
+	return false
+}
+
+// IDプロバイダーのアクセストークンを無効にする
+func (interactor *LoginInteractor) Logout(providerName string, accessToken string) error {
+	if accessToken == "" {
+		return domain.UnauthorizedError("access token is required")
+	}
+	provider, err := interactor.IdentityProviders.Get(providerName)
+	if err != nil {
+		return err
+	}
+	return provider.Revoke(accessToken)
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+func TestIsAdminSubject(t *testing.T) {
+	interactor := LoginInteractor{AdminSubjects: []domain.Identity{{Provider: "line", Subject: "U1234"}}}
+	tests := []struct {
+		provider string
+		subject  string
+		want     bool
+	}{
+		{"line", "U1234", true},
+		{"oidc", "U1234", false}, // 別のプロバイダーで同じIDを名乗っても管理者にしない
+		{"line", "U5678", false},
+	}
+	for _, tt := range tests {
+		if got := interactor.isAdminSubject(tt.provider, tt.subject); got != tt.want {
+			t.Errorf("isAdminSubject(%q, %q) = %v, want %v", tt.provider, tt.subject, got, tt.want)
+		}
+	}
+}
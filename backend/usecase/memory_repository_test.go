@@ -0,0 +1,377 @@
+package usecase
+
+import (
+	"sort"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// テスト用にメモリ上で動くリポジトリ。見つからなければdatabaseのものと同じくdomain.ErrNotFoundを返す
+
+type memoryTsundokuRepository struct {
+	tsundokus map[int]domain.Tsundoku
+	tags      map[int][]domain.Tag // 積読ごとのタグ
+	nextID    int
+}
+
+func newMemoryTsundokuRepository(tsundokus ...domain.Tsundoku) *memoryTsundokuRepository {
+	repository := &memoryTsundokuRepository{tsundokus: map[int]domain.Tsundoku{}, tags: map[int][]domain.Tag{}, nextID: 1}
+	for _, tsundoku := range tsundokus {
+		repository.tsundokus[tsundoku.ID] = tsundoku
+		if tsundoku.ID >= repository.nextID {
+			repository.nextID = tsundoku.ID + 1
+		}
+	}
+	return repository
+}
+
+// IDの順に並べる
+func (repository *memoryTsundokuRepository) list(match func(domain.Tsundoku) bool) []domain.Tsundoku {
+	tsundokus := []domain.Tsundoku{}
+	for _, tsundoku := range repository.tsundokus {
+		if match(tsundoku) {
+			tsundokus = append(tsundokus, tsundoku)
+		}
+	}
+	sort.Slice(tsundokus, func(i, j int) bool { return tsundokus[i].ID < tsundokus[j].ID })
+	return tsundokus
+}
+
+func (repository *memoryTsundokuRepository) Store(tsundoku domain.Tsundoku) (domain.Tsundoku, error) {
+	tsundoku.ID = repository.nextID
+	repository.nextID++
+	repository.tsundokus[tsundoku.ID] = tsundoku
+	return tsundoku, nil
+}
+
+func (repository *memoryTsundokuRepository) Select(userID int) ([]domain.Tsundoku, error) {
+	return repository.list(func(t domain.Tsundoku) bool { return t.UserID == userID && t.DeletedAt == nil }), nil
+}
+
+// 読書状態とカテゴリだけで絞り込む
+func (repository *memoryTsundokuRepository) SelectByFilter(userID int, filter TsundokuFilter, page Page) ([]domain.Tsundoku, error) {
+	return repository.list(func(t domain.Tsundoku) bool {
+		if t.UserID != userID || t.DeletedAt != nil {
+			return false
+		}
+		if len(filter.Statuses) > 0 && !containsString(filter.Statuses, t.Status) {
+			return false
+		}
+		return len(filter.Categories) == 0 || containsString(filter.Categories, t.Category)
+	}), nil
+}
+
+func (repository *memoryTsundokuRepository) SelectByID(id int) (domain.Tsundoku, error) {
+	tsundoku, ok := repository.tsundokus[id]
+	if !ok || tsundoku.DeletedAt != nil {
+		return domain.Tsundoku{}, domain.ErrNotFound
+	}
+	return tsundoku, nil
+}
+
+func (repository *memoryTsundokuRepository) SelectTags(userID int, tsundokuIDs []int) (map[int][]domain.Tag, error) {
+	tags := map[int][]domain.Tag{}
+	for _, id := range tsundokuIDs {
+		if len(repository.tags[id]) > 0 {
+			tags[id] = repository.tags[id]
+		}
+	}
+	return tags, nil
+}
+
+func (repository *memoryTsundokuRepository) Update(tsundoku domain.Tsundoku) error {
+	repository.tsundokus[tsundoku.ID] = tsundoku
+	return nil
+}
+
+func (repository *memoryTsundokuRepository) Search(userID int, tokens []string, limit int) ([]domain.SearchResult, error) {
+	return []domain.SearchResult{}, nil
+}
+
+func (repository *memoryTsundokuRepository) DeleteByUser(userID, id int) error {
+	tsundoku, err := repository.SelectByID(id)
+	if err != nil || tsundoku.UserID != userID {
+		return domain.ErrNotFound
+	}
+	now := time.Now()
+	tsundoku.DeletedAt = &now
+	repository.tsundokus[id] = tsundoku
+	return nil
+}
+
+func (repository *memoryTsundokuRepository) SelectDeleted(userID int) ([]domain.Tsundoku, error) {
+	return repository.list(func(t domain.Tsundoku) bool { return t.UserID == userID && t.DeletedAt != nil }), nil
+}
+
+func (repository *memoryTsundokuRepository) SelectDeletedByID(id int) (domain.Tsundoku, error) {
+	tsundoku, ok := repository.tsundokus[id]
+	if !ok || tsundoku.DeletedAt == nil {
+		return domain.Tsundoku{}, domain.ErrNotFound
+	}
+	return tsundoku, nil
+}
+
+func (repository *memoryTsundokuRepository) RestoreByUser(userID, id int) error {
+	tsundoku, err := repository.SelectDeletedByID(id)
+	if err != nil || tsundoku.UserID != userID {
+		return domain.ErrNotFound
+	}
+	tsundoku.DeletedAt = nil
+	repository.tsundokus[id] = tsundoku
+	return nil
+}
+
+func (repository *memoryTsundokuRepository) PurgeByUser(userID, id int) error {
+	tsundoku, err := repository.SelectDeletedByID(id)
+	if err != nil || tsundoku.UserID != userID {
+		return domain.ErrNotFound
+	}
+	delete(repository.tsundokus, id)
+	return nil
+}
+
+func (repository *memoryTsundokuRepository) PurgeDeletedBefore(before time.Time) error {
+	for id, tsundoku := range repository.tsundokus {
+		if tsundoku.DeletedAt != nil && tsundoku.DeletedAt.Before(before) {
+			delete(repository.tsundokus, id)
+		}
+	}
+	return nil
+}
+
+type memoryTagRepository struct {
+	tags   map[int]domain.Tag
+	nextID int
+}
+
+func newMemoryTagRepository(tags ...domain.Tag) *memoryTagRepository {
+	repository := &memoryTagRepository{tags: map[int]domain.Tag{}, nextID: 1}
+	for _, tag := range tags {
+		repository.tags[tag.ID] = tag
+		if tag.ID >= repository.nextID {
+			repository.nextID = tag.ID + 1
+		}
+	}
+	return repository
+}
+
+func (repository *memoryTagRepository) list(match func(domain.Tag) bool) []domain.Tag {
+	tags := []domain.Tag{}
+	for _, tag := range repository.tags {
+		if match(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].ID < tags[j].ID })
+	return tags
+}
+
+func (repository *memoryTagRepository) Store(tag domain.Tag) (domain.Tag, error) {
+	tag.ID = repository.nextID
+	repository.nextID++
+	repository.tags[tag.ID] = tag
+	return tag, nil
+}
+
+func (repository *memoryTagRepository) Select(tagIDs []int) ([]domain.Tag, error) {
+	return repository.list(func(t domain.Tag) bool {
+		for _, id := range tagIDs {
+			if t.ID == id && t.DeletedAt == nil {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (repository *memoryTagRepository) SelectByUser(userID int, page Page) ([]domain.Tag, error) {
+	return repository.list(func(t domain.Tag) bool { return t.UserID == userID && t.DeletedAt == nil }), nil
+}
+
+func (repository *memoryTagRepository) SelectByID(id int) (domain.Tag, error) {
+	tag, ok := repository.tags[id]
+	if !ok || tag.DeletedAt != nil {
+		return domain.Tag{}, domain.ErrNotFound
+	}
+	return tag, nil
+}
+
+func (repository *memoryTagRepository) SelectByName(userID int, name string) (domain.Tag, error) {
+	tags := repository.list(func(t domain.Tag) bool { return t.UserID == userID && t.Name == name && t.DeletedAt == nil })
+	if len(tags) == 0 {
+		return domain.Tag{}, domain.ErrNotFound
+	}
+	return tags[0], nil
+}
+
+func (repository *memoryTagRepository) Update(tag domain.Tag) error {
+	repository.tags[tag.ID] = tag
+	return nil
+}
+
+func (repository *memoryTagRepository) Merge(userID, sourceID, targetID int) error {
+	delete(repository.tags, sourceID)
+	return nil
+}
+
+func (repository *memoryTagRepository) DeleteByUser(userID, id int) error {
+	tag, err := repository.SelectByID(id)
+	if err != nil || tag.UserID != userID {
+		return domain.ErrNotFound
+	}
+	now := time.Now()
+	tag.DeletedAt = &now
+	repository.tags[id] = tag
+	return nil
+}
+
+func (repository *memoryTagRepository) SelectDeleted(userID int) ([]domain.Tag, error) {
+	return repository.list(func(t domain.Tag) bool { return t.UserID == userID && t.DeletedAt != nil }), nil
+}
+
+func (repository *memoryTagRepository) RestoreByUser(userID, id int) error {
+	tag, ok := repository.tags[id]
+	if !ok || tag.UserID != userID || tag.DeletedAt == nil {
+		return domain.ErrNotFound
+	}
+	tag.DeletedAt = nil
+	repository.tags[id] = tag
+	return nil
+}
+
+func (repository *memoryTagRepository) PurgeByUser(userID, id int) error {
+	tag, ok := repository.tags[id]
+	if !ok || tag.UserID != userID || tag.DeletedAt == nil {
+		return domain.ErrNotFound
+	}
+	delete(repository.tags, id)
+	return nil
+}
+
+func (repository *memoryTagRepository) PurgeDeletedBefore(before time.Time) error {
+	return nil
+}
+
+type memoryTsundokuTagRepository struct {
+	tsundokuTags []domain.TsundokuTag
+}
+
+func (repository *memoryTsundokuTagRepository) Store(tsundokuTag domain.TsundokuTag) error {
+	for _, existing := range repository.tsundokuTags {
+		if existing.TsundokuID == tsundokuTag.TsundokuID && existing.TagID == tsundokuTag.TagID && existing.DeletedAt == nil {
+			return nil
+		}
+	}
+	repository.tsundokuTags = append(repository.tsundokuTags, tsundokuTag)
+	return nil
+}
+
+func (repository *memoryTsundokuTagRepository) Select(userID int) ([]domain.TsundokuTag, error) {
+	tsundokuTags := []domain.TsundokuTag{}
+	for _, tsundokuTag := range repository.tsundokuTags {
+		if tsundokuTag.UserID == userID && tsundokuTag.DeletedAt == nil {
+			tsundokuTags = append(tsundokuTags, tsundokuTag)
+		}
+	}
+	return tsundokuTags, nil
+}
+
+func (repository *memoryTsundokuTagRepository) SelectByMultiIDs(tsundokuID, userID int) ([]domain.TsundokuTag, error) {
+	tsundokuTags := []domain.TsundokuTag{}
+	for _, tsundokuTag := range repository.tsundokuTags {
+		if tsundokuTag.TsundokuID == tsundokuID && tsundokuTag.UserID == userID && tsundokuTag.DeletedAt == nil {
+			tsundokuTags = append(tsundokuTags, tsundokuTag)
+		}
+	}
+	return tsundokuTags, nil
+}
+
+func (repository *memoryTsundokuTagRepository) DeleteByUser(userID, tsundokuID, tagID int) error {
+	for i, tsundokuTag := range repository.tsundokuTags {
+		if tsundokuTag.TsundokuID == tsundokuID && tsundokuTag.TagID == tagID && tsundokuTag.UserID == userID && tsundokuTag.DeletedAt == nil {
+			repository.tsundokuTags = append(repository.tsundokuTags[:i], repository.tsundokuTags[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+type memoryUserRepository struct {
+	users map[int]domain.User
+}
+
+func newMemoryUserRepository(users ...domain.User) *memoryUserRepository {
+	repository := &memoryUserRepository{users: map[int]domain.User{}}
+	for _, user := range users {
+		repository.users[user.ID] = user
+	}
+	return repository
+}
+
+func (repository *memoryUserRepository) Store(user domain.User) (domain.User, error) {
+	user.ID = len(repository.users) + 1
+	repository.users[user.ID] = user
+	return user, nil
+}
+
+func (repository *memoryUserRepository) Select() ([]domain.User, error) {
+	users := []domain.User{}
+	for _, user := range repository.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (repository *memoryUserRepository) SelectByID(id int) (domain.User, error) {
+	user, ok := repository.users[id]
+	if !ok {
+		return domain.User{}, domain.ErrNotFound
+	}
+	return user, nil
+}
+
+func (repository *memoryUserRepository) Prepare(identity domain.Identity, user domain.User) (domain.User, error) {
+	return repository.Store(user)
+}
+
+func (repository *memoryUserRepository) UpdateProfile(user domain.User) (domain.User, error) {
+	repository.users[user.ID] = user
+	return user, nil
+}
+
+func (repository *memoryUserRepository) UpdateRole(id int, role string) error {
+	user, err := repository.SelectByID(id)
+	if err != nil {
+		return err
+	}
+	user.Role = role
+	repository.users[id] = user
+	return nil
+}
+
+func (repository *memoryUserRepository) CancelDeletion(id int) error {
+	user, err := repository.SelectByID(id)
+	if err != nil {
+		return err
+	}
+	user.DeletionScheduledAt = nil
+	repository.users[id] = user
+	return nil
+}
+
+func (repository *memoryUserRepository) CountByRole(role string) (int, error) {
+	count := 0
+	for _, user := range repository.users {
+		if user.Role == role {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (repository *memoryUserRepository) Delete(id int) error {
+	delete(repository.users, id)
+	return nil
+}
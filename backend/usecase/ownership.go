@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// ユーザーが管理している積読を取得。存在しなければErrNotFound、他のユーザーのものならErrForbidden
+func ownedTsundoku(repository TsundokuRepository, userID, id int) (domain.Tsundoku, error) {
+	tsundoku, err := repository.SelectByID(id)
+	if err != nil {
+		return domain.Tsundoku{}, tsundokuError(err)
+	}
+	if tsundoku.UserID != userID {
+		return domain.Tsundoku{}, domain.ForbiddenError("tsundoku is not yours")
+	}
+	return tsundoku, nil
+}
+
+// ゴミ箱に入っているユーザーの積読を取得
+func ownedDeletedTsundoku(repository TsundokuRepository, userID, id int) (domain.Tsundoku, error) {
+	tsundoku, err := repository.SelectDeletedByID(id)
+	if err != nil {
+		return domain.Tsundoku{}, tsundokuError(err)
+	}
+	if tsundoku.UserID != userID {
+		return domain.Tsundoku{}, domain.ForbiddenError("tsundoku is not yours")
+	}
+	return tsundoku, nil
+}
+
+// 見つからなかったときのメッセージを積読のものにする
+func tsundokuError(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NotFoundError("tsundoku not found")
+	}
+	return err
+}
+
+// ユーザーのタグを取得
+func ownedTag(repository TagRepository, userID, id int) (domain.Tag, error) {
+	tag, err := repository.SelectByID(id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.Tag{}, domain.NotFoundError("tag not found")
+	}
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	if tag.UserID != userID {
+		return domain.Tag{}, domain.ForbiddenError("tag is not yours")
+	}
+	return tag, nil
+}
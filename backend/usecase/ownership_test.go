@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+const (
+	alice = 1
+	bob   = 2
+)
+
+// aliceとbobがそれぞれ積読、ゴミ箱の積読、タグ、ゴミ箱のタグを持っている
+type ownershipFixture struct {
+	tsundokus    *memoryTsundokuRepository
+	tags         *memoryTagRepository
+	tsundokuTags *memoryTsundokuTagRepository
+}
+
+const (
+	aliceTsundoku        = 1
+	aliceDeletedTsundoku = 2
+	bobTsundoku          = 3
+	bobDeletedTsundoku   = 4
+	missingID            = 99
+	aliceTag             = 10
+	bobTag               = 20
+	bobDeletedTag        = 21
+)
+
+func newOwnershipFixture() ownershipFixture {
+	deletedAt := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+	tsundoku := func(id, userID int, deleted bool) domain.Tsundoku {
+		t := domain.Tsundoku{ID: id, UserID: userID, Title: "title", Category: domain.CategorySite, Status: domain.StatusUnread, Priority: domain.PriorityNormal}
+		if deleted {
+			t.DeletedAt = &deletedAt
+		}
+		return t
+	}
+	return ownershipFixture{
+		tsundokus: newMemoryTsundokuRepository(
+			tsundoku(aliceTsundoku, alice, false),
+			tsundoku(aliceDeletedTsundoku, alice, true),
+			tsundoku(bobTsundoku, bob, false),
+			tsundoku(bobDeletedTsundoku, bob, true),
+		),
+		tags: newMemoryTagRepository(
+			domain.Tag{ID: aliceTag, UserID: alice, Name: "Go"},
+			domain.Tag{ID: bobTag, UserID: bob, Name: "Go"},
+			domain.Tag{ID: bobDeletedTag, UserID: bob, Name: "DB", DeletedAt: &deletedAt},
+		),
+		tsundokuTags: &memoryTsundokuTagRepository{tsundokuTags: []domain.TsundokuTag{
+			{TsundokuID: aliceTsundoku, TagID: aliceTag, UserID: alice},
+			{TsundokuID: bobTsundoku, TagID: bobTag, UserID: bob},
+		}},
+	}
+}
+
+func (fixture ownershipFixture) tsundokuInteractor() *TsundokuInteractor {
+	return &TsundokuInteractor{TsundokuRepository: fixture.tsundokus}
+}
+
+func (fixture ownershipFixture) tagInteractor() *TagInteractor {
+	return &TagInteractor{TagRepository: fixture.tags, TsundokuRepository: fixture.tsundokus, TsundokuTagRepository: fixture.tsundokuTags}
+}
+
+func (fixture ownershipFixture) trashInteractor() *TrashInteractor {
+	return &TrashInteractor{TsundokuRepository: fixture.tsundokus, TagRepository: fixture.tags}
+}
+
+// bobのデータ。aliceの操作で変わっていないことを確かめる
+func (fixture ownershipFixture) bobData() interface{} {
+	tsundokus := fixture.tsundokus.list(func(t domain.Tsundoku) bool { return t.UserID == bob })
+	tags := fixture.tags.list(func(t domain.Tag) bool { return t.UserID == bob })
+	tsundokuTags := []domain.TsundokuTag{}
+	for _, tsundokuTag := range fixture.tsundokuTags.tsundokuTags {
+		if tsundokuTag.UserID == bob {
+			tsundokuTags = append(tsundokuTags, tsundokuTag)
+		}
+	}
+	return []interface{}{tsundokus, tags, tsundokuTags}
+}
+
+// aliceが自分のもの、bobのもの、存在しないものを操作する
+func TestOwnership(t *testing.T) {
+	title := "new title"
+	tests := []struct {
+		name string
+		call func(fixture ownershipFixture) error
+		want error
+	}{
+		{"update own tsundoku", func(f ownershipFixture) error {
+			_, err := f.tsundokuInteractor().Update(alice, aliceTsundoku, TsundokuUpdate{Title: &title})
+			return err
+		}, nil},
+		{"update another user's tsundoku", func(f ownershipFixture) error {
+			_, err := f.tsundokuInteractor().Update(alice, bobTsundoku, TsundokuUpdate{Title: &title})
+			return err
+		}, domain.ErrForbidden},
+		{"update missing tsundoku", func(f ownershipFixture) error {
+			_, err := f.tsundokuInteractor().Update(alice, missingID, TsundokuUpdate{Title: &title})
+			return err
+		}, domain.ErrNotFound},
+
+		{"delete own tsundoku", func(f ownershipFixture) error {
+			return f.tsundokuInteractor().Delete(alice, aliceTsundoku)
+		}, nil},
+		{"delete another user's tsundoku", func(f ownershipFixture) error {
+			return f.tsundokuInteractor().Delete(alice, bobTsundoku)
+		}, domain.ErrForbidden},
+		{"delete missing tsundoku", func(f ownershipFixture) error {
+			return f.tsundokuInteractor().Delete(alice, missingID)
+		}, domain.ErrNotFound},
+
+		{"change status of own tsundoku", func(f ownershipFixture) error {
+			_, err := f.tsundokuInteractor().ChangeStatus(alice, aliceTsundoku, domain.StatusReading)
+			return err
+		}, nil},
+		{"change status of another user's tsundoku", func(f ownershipFixture) error {
+			_, err := f.tsundokuInteractor().ChangeStatus(alice, bobTsundoku, domain.StatusReading)
+			return err
+		}, domain.ErrForbidden},
+		{"change status of missing tsundoku", func(f ownershipFixture) error {
+			_, err := f.tsundokuInteractor().ChangeStatus(alice, missingID, domain.StatusReading)
+			return err
+		}, domain.ErrNotFound},
+
+		{"tag own tsundoku", func(f ownershipFixture) error {
+			_, err := f.tagInteractor().AddToTsundoku(alice, aliceTsundoku, "DB")
+			return err
+		}, nil},
+		{"tag another user's tsundoku", func(f ownershipFixture) error {
+			_, err := f.tagInteractor().AddToTsundoku(alice, bobTsundoku, "DB")
+			return err
+		}, domain.ErrForbidden},
+		{"tag missing tsundoku", func(f ownershipFixture) error {
+			_, err := f.tagInteractor().AddToTsundoku(alice, missingID, "DB")
+			return err
+		}, domain.ErrNotFound},
+
+		{"untag own tsundoku", func(f ownershipFixture) error {
+			return f.tagInteractor().DeleteFromTsundoku(alice, aliceTsundoku, aliceTag)
+		}, nil},
+		{"untag another user's tsundoku", func(f ownershipFixture) error {
+			return f.tagInteractor().DeleteFromTsundoku(alice, bobTsundoku, bobTag)
+		}, domain.ErrForbidden},
+		{"untag another user's tag from own tsundoku", func(f ownershipFixture) error {
+			return f.tagInteractor().DeleteFromTsundoku(alice, aliceTsundoku, bobTag)
+		}, domain.ErrNotFound},
+		{"untag missing tsundoku", func(f ownershipFixture) error {
+			return f.tagInteractor().DeleteFromTsundoku(alice, missingID, aliceTag)
+		}, domain.ErrNotFound},
+
+		{"restore own tsundoku", func(f ownershipFixture) error {
+			return f.trashInteractor().RestoreTsundoku(alice, aliceDeletedTsundoku)
+		}, nil},
+		{"restore another user's tsundoku", func(f ownershipFixture) error {
+			return f.trashInteractor().RestoreTsundoku(alice, bobDeletedTsundoku)
+		}, domain.ErrForbidden},
+		{"restore tsundoku not in trash", func(f ownershipFixture) error {
+			return f.trashInteractor().RestoreTsundoku(alice, aliceTsundoku)
+		}, domain.ErrNotFound},
+		{"restore another user's tag", func(f ownershipFixture) error {
+			return f.trashInteractor().RestoreTag(alice, bobDeletedTag)
+		}, domain.ErrNotFound},
+
+		{"purge own tsundoku", func(f ownershipFixture) error {
+			return f.trashInteractor().PurgeTsundoku(alice, aliceDeletedTsundoku)
+		}, nil},
+		{"purge another user's tsundoku", func(f ownershipFixture) error {
+			return f.trashInteractor().PurgeTsundoku(alice, bobDeletedTsundoku)
+		}, domain.ErrForbidden},
+		{"purge missing tsundoku", func(f ownershipFixture) error {
+			return f.trashInteractor().PurgeTsundoku(alice, missingID)
+		}, domain.ErrNotFound},
+		{"purge another user's tag", func(f ownershipFixture) error {
+			return f.trashInteractor().PurgeTag(alice, bobDeletedTag)
+		}, domain.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newOwnershipFixture()
+			before := fixture.bobData()
+			err := tt.call(fixture)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else if !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+			if after := fixture.bobData(); !reflect.DeepEqual(before, after) {
+				t.Errorf("bob's data changed:\nbefore %+v\nafter  %+v", before, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// 並び順
+const (
+	SortCreated      = "created"
+	SortDeadline     = "deadline"
+	SortRequiredTime = "requiredTime"
+	SortTitle        = "title"
+	SortName         = "name"
+)
+
+// 1ページの件数の上限
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// 一覧の取得範囲。Afterの次の行から、Sortの順にLimit件取得する
+type Page struct {
+	Sort  string
+	Desc  bool
+	Limit int // 0なら制限しない
+	After *Cursor
+}
+
+// 前のページの最後の行。並び順の値とIDで次の行を決める
+type Cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    int    `json:"i"`
+}
+
+// カーソルをURLで使える文字列にする
+func (cursor Cursor) Encode() string {
+	b, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, domain.ValidationError("invalid cursor")
+	}
+	cursor := Cursor{}
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, domain.ValidationError("invalid cursor")
+	}
+	return &cursor, nil
+}
+
+// sortsのどれかで並べる取得範囲か確かめ、空の並び順をデフォルトにする
+func (page *Page) normalize(sorts ...string) error {
+	if page.Sort == "" {
+		page.Sort = SortCreated
+	}
+	valid := false
+	for _, sort := range sorts {
+		if page.Sort == sort {
+			valid = true
+		}
+	}
+	if !valid {
+		return domain.ValidationError("sort must be one of " + strings.Join(sorts, ", "))
+	}
+	if page.Limit < 0 || page.Limit > MaxPageLimit {
+		return domain.ValidationError("limit must be between 1 and " + strconv.Itoa(MaxPageLimit))
+	}
+	if page.After != nil {
+		// 並び順が変わるとカーソルの値の意味も変わる
+		if page.After.Sort != page.Sort || page.After.Desc != page.Desc {
+			return domain.ValidationError("cursor does not match sort")
+		}
+		if page.Limit == 0 {
+			page.Limit = DefaultPageLimit
+		}
+	}
+	return nil
+}
+
+// 次のページがあるか調べるため1件多く取得する件数
+func (page Page) fetchLimit() int {
+	if page.Limit == 0 {
+		return 0
+	}
+	return page.Limit + 1
+}
+
+// 1件多く取得した結果から、次のページのカーソルに使う最後の行の位置を返す。次のページがなければ-1
+func (page Page) lastIndex(count int) int {
+	if page.Limit == 0 || count <= page.Limit {
+		return -1
+	}
+	return page.Limit - 1
+}
+
+func (page Page) cursor(value string, id int) string {
+	return Cursor{Sort: page.Sort, Desc: page.Desc, Value: value, ID: id}.Encode()
+}
+
+// 並び順の値。データベースの並び順の式と同じ値にする
+func tsundokuSortValue(tsundoku domain.Tsundoku, sort string) string {
+	switch sort {
+	case SortDeadline:
+		// 締め切りなしは最後
+		if !tsundoku.HasDeadline() {
+			return "infinity"
+		}
+		return tsundoku.Deadline.Format(time.RFC3339Nano)
+	case SortRequiredTime:
+		return strconv.Itoa(tsundoku.RequiredMinutes)
+	case SortTitle:
+		return tsundoku.Title
+	}
+	return tsundoku.CreatedAt.Format(time.RFC3339Nano)
+}
+
+func tagSortValue(tag domain.Tag, sort string) string {
+	if sort == SortName {
+		return tag.Name
+	}
+	return strconv.Itoa(tag.ID)
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// 同じIPアドレスから続けて使われたときに最終利用日時を更新する間隔
+const tokenTouchInterval = time.Minute
+
+type PersonalAccessTokenInteractor struct {
+	PersonalAccessTokenRepository PersonalAccessTokenRepository
+}
+
+// トークンを発行する。トークンそのものは発行したときにしか返さない
+func (interactor *PersonalAccessTokenInteractor) Add(userID int, name string, scopes []string) (domain.PersonalAccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.PersonalAccessToken{}, "", domain.ValidationError("name is required")
+	}
+	if len(scopes) == 0 {
+		return domain.PersonalAccessToken{}, "", domain.ValidationError("scopes is required")
+	}
+	for _, scope := range scopes {
+		if !domain.IsValidScope(scope) {
+			return domain.PersonalAccessToken{}, "", domain.ValidationError("invalid scope: " + scope)
+		}
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return domain.PersonalAccessToken{}, "", domain.InternalError(err)
+	}
+	secret := domain.PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	token, err := interactor.PersonalAccessTokenRepository.Store(domain.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashToken(secret),
+		Scopes:    domain.Scopes(scopes),
+	})
+	if err != nil {
+		return domain.PersonalAccessToken{}, "", err
+	}
+	return token, secret, nil
+}
+
+func (interactor *PersonalAccessTokenInteractor) GetInfoByUser(userID int) ([]domain.PersonalAccessToken, error) {
+	return interactor.PersonalAccessTokenRepository.SelectByUser(userID)
+}
+
+// ユーザーのトークンを無効にする
+func (interactor *PersonalAccessTokenInteractor) Delete(userID, id int) error {
+	token, err := interactor.PersonalAccessTokenRepository.SelectByID(id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NotFoundError("token not found")
+	}
+	if err != nil {
+		return err
+	}
+	if token.UserID != userID {
+		return domain.ForbiddenError("token is not yours")
+	}
+	return interactor.PersonalAccessTokenRepository.DeleteByUser(userID, id)
+}
+
+// トークンを確かめて、使った日時とIPアドレスを記録する。無効ならdomain.ErrUnauthorized
+func (interactor *PersonalAccessTokenInteractor) Authenticate(secret string, ip string) (domain.PersonalAccessToken, error) {
+	token, err := interactor.PersonalAccessTokenRepository.SelectByTokenHash(hashToken(secret))
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.PersonalAccessToken{}, domain.UnauthorizedError("invalid personal access token")
+	}
+	if err != nil {
+		return domain.PersonalAccessToken{}, err
+	}
+
+	// リクエストのたびに書き込まないように、少し前に同じIPアドレスから使われていたら記録しない
+	now := time.Now()
+	if token.LastUsedAt == nil || token.LastUsedIP != ip || now.Sub(*token.LastUsedAt) > tokenTouchInterval {
+		if err := interactor.PersonalAccessTokenRepository.Touch(token.ID, now, ip); err != nil {
+			return domain.PersonalAccessToken{}, err
+		}
+		token.LastUsedAt, token.LastUsedIP = &now, ip
+	}
+	return token, nil
+}
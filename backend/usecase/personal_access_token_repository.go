@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type PersonalAccessTokenRepository interface {
+	Store(token domain.PersonalAccessToken) (domain.PersonalAccessToken, error)
+	SelectByUser(userID int) ([]domain.PersonalAccessToken, error)
+	SelectByID(id int) (domain.PersonalAccessToken, error)
+	SelectByTokenHash(tokenHash string) (domain.PersonalAccessToken, error)
+	// 最後に使った日時とIPアドレスを記録する
+	Touch(id int, usedAt time.Time, ip string) error
+	DeleteByUser(userID, id int) error
+}
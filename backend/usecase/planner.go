@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// 計画できる空き時間の上限(分)
+const MaxPlanMinutes = 24 * 60
+
+// 本を一度に読む時間の上限(分)。長い本は少しずつ読む
+const bookChunkMinutes = 30
+
+// 空き時間に収まる積読の組み合わせのうち、価値の合計が最も大きいものを選んで読む順に並べる
+// ページ数の分かる本は、pagesPerMinuteの速さで読めるだけのページを読む。それ以外の読む時間が分からない積読は選ばない
+func PlanFreeTime(tsundokus []domain.Tsundoku, freeMinutes int, pagesPerMinute float64, now time.Time) domain.Plan {
+	candidates := []domain.PlanItem{}
+	for _, tsundoku := range tsundokus {
+		item := domain.PlanItem{Tsundoku: tsundoku, Minutes: tsundoku.RequiredMinutes, Value: planValue(tsundoku, now)}
+		if remaining := tsundoku.RemainingPages(); remaining > 0 {
+			item.Pages, item.Minutes = bookChunk(remaining, freeMinutes, pagesPerMinute)
+			item.EndPage = tsundoku.CurrentPage + item.Pages
+		}
+		if item.Minutes > 0 && item.Minutes <= freeMinutes {
+			candidates = append(candidates, item)
+		}
+	}
+	// 同じ入力なら同じ計画になるように
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+
+	// 0-1ナップサック問題。best[m]はm分以内で読める組み合わせの価値の最大
+	best := make([]float64, freeMinutes+1)
+	chosen := make([][]bool, len(candidates))
+	for i, candidate := range candidates {
+		chosen[i] = make([]bool, freeMinutes+1)
+		for m := freeMinutes; m >= candidate.Minutes; m-- {
+			if value := best[m-candidate.Minutes] + candidate.Value; value > best[m] {
+				best[m] = value
+				chosen[i][m] = true
+			}
+		}
+	}
+
+	plan := domain.Plan{FreeMinutes: freeMinutes, Items: []domain.PlanItem{}, PagesPerMinute: math.Round(pagesPerMinute*100) / 100}
+	for i, m := len(candidates)-1, freeMinutes; i >= 0; i-- {
+		if chosen[i][m] {
+			plan.Items = append(plan.Items, candidates[i])
+			m -= candidates[i].Minutes
+		}
+	}
+
+	// 締め切りが近いものから、締め切りがなければ価値の大きいものから読む
+	sort.SliceStable(plan.Items, func(i, j int) bool {
+		a, b := plan.Items[i], plan.Items[j]
+		if a.HasDeadline() != b.HasDeadline() {
+			return a.HasDeadline()
+		}
+		if a.HasDeadline() && !a.Deadline.Equal(b.Deadline) {
+			return a.Deadline.Before(b.Deadline)
+		}
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		return a.ID < b.ID
+	})
+	for i := range plan.Items {
+		plan.Items[i].StartMinute = plan.TotalMinutes
+		plan.TotalMinutes += plan.Items[i].Minutes
+	}
+	plan.LeftoverMinutes = freeMinutes - plan.TotalMinutes
+	return plan
+}
+
+// 残りのページのうち、空き時間とbookChunkMinutesに収まるだけのページ数と、その時間
+func bookChunk(remainingPages, freeMinutes int, pagesPerMinute float64) (int, int) {
+	minutes := freeMinutes
+	if minutes > bookChunkMinutes {
+		minutes = bookChunkMinutes
+	}
+	pages := int(float64(minutes) * pagesPerMinute)
+	if pages > remainingPages {
+		pages = remainingPages
+	}
+	if pages <= 0 {
+		return 0, 0
+	}
+	// 小数の誤差で空き時間を超えないようにする
+	needed := int(math.Ceil(float64(pages) / pagesPerMinute))
+	if needed > minutes {
+		needed = minutes
+	}
+	return pages, needed
+}
+
+// 積読を読む価値。1を基本に、締め切りが近いほど(最大+3)、積んでから長いほど(最大+1)、優先度が高いほど(最大+2)大きくする
+func planValue(tsundoku domain.Tsundoku, now time.Time) float64 {
+	value := 1 + deadlineUrgency(tsundoku, now) + staleness(tsundoku, now) + priorityWeight(tsundoku)
+	// 表示しやすいように丸める
+	return math.Round(value*1000) / 1000
+}
+
+// 締め切りを過ぎていれば3で、1週間先になるごとに半分にする。締め切りがなければ0
+func deadlineUrgency(tsundoku domain.Tsundoku, now time.Time) float64 {
+	if !tsundoku.HasDeadline() {
+		return 0
+	}
+	days := math.Max(0, tsundoku.Deadline.Sub(now).Hours()/24)
+	return 3 * math.Pow(0.5, days/7)
+}
+
+// 積んでからの日数を0から1にする。90日で最大
+func staleness(tsundoku domain.Tsundoku, now time.Time) float64 {
+	days := math.Max(0, now.Sub(tsundoku.CreatedAt).Hours()/24)
+	return math.Min(days/90, 1)
+}
+
+// 優先度の1から5を0から2にする
+func priorityWeight(tsundoku domain.Tsundoku) float64 {
+	return float64(tsundoku.Priority-domain.PriorityLowest) / float64(domain.PriorityHighest-domain.PriorityLowest) * 2
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+var planNow = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// 今日積んだ、締め切りのない普通の優先度のサイト。価値は2になる
+func planSite(id, minutes int) domain.Tsundoku {
+	return domain.Tsundoku{ID: id, Category: domain.CategorySite, RequiredMinutes: minutes, Priority: domain.PriorityNormal, CreatedAt: planNow}
+}
+
+func withDeadline(tsundoku domain.Tsundoku, deadline time.Time) domain.Tsundoku {
+	tsundoku.Deadline = deadline
+	return tsundoku
+}
+
+func withPriority(tsundoku domain.Tsundoku, priority int) domain.Tsundoku {
+	tsundoku.Priority = priority
+	return tsundoku
+}
+
+func planItemIDs(plan domain.Plan) []int {
+	ids := []int{}
+	for _, item := range plan.Items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestPlanFreeTime(t *testing.T) {
+	tests := []struct {
+		name        string
+		tsundokus   []domain.Tsundoku
+		freeMinutes int
+		want        []int // 読む順の積読のID
+		wantTotal   int
+	}{
+		{"empty input", nil, 45, []int{}, 0},
+		{"zero-minute budget", []domain.Tsundoku{planSite(1, 5), planSite(2, 10)}, 0, []int{}, 0},
+		{"items longer than the budget", []domain.Tsundoku{planSite(1, 50), planSite(2, 46)}, 45, []int{}, 0},
+		{"unknown required time", []domain.Tsundoku{planSite(1, 0), planSite(2, 10)}, 45, []int{2}, 10},
+		{"exactly the budget", []domain.Tsundoku{planSite(1, 45)}, 45, []int{1}, 45},
+		// 一つずつ詰めると30分のものしか入らないが、25分と20分の二つのほうが価値が大きい
+		{"better combination", []domain.Tsundoku{planSite(1, 30), planSite(2, 25), planSite(3, 20)}, 45, []int{2, 3}, 45},
+		{"higher value wins", []domain.Tsundoku{planSite(1, 30), withPriority(planSite(2, 30), domain.PriorityHighest)}, 45, []int{2}, 30},
+		// 価値と時間が同じならIDの小さいものを選ぶ。入力の順には左右されない
+		{"ties", []domain.Tsundoku{planSite(3, 30), planSite(1, 30), planSite(2, 30)}, 45, []int{1}, 30},
+		{"ties in order", []domain.Tsundoku{planSite(3, 10), planSite(1, 10), planSite(2, 10)}, 45, []int{1, 2, 3}, 30},
+		// 締め切りのあるものが先で、締め切りの近い順。価値が小さくても先に読む
+		{"deadlines first", []domain.Tsundoku{
+			withPriority(planSite(1, 10), domain.PriorityHighest),
+			withDeadline(withPriority(planSite(2, 10), domain.PriorityLowest), planNow.AddDate(0, 0, 60)),
+			withDeadline(planSite(3, 10), planNow.AddDate(0, 0, 30)),
+		}, 45, []int{3, 2, 1}, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanFreeTime(tt.tsundokus, tt.freeMinutes, domain.DefaultPagesPerMinute, planNow)
+			if got := planItemIDs(plan); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got items %v, want %v", got, tt.want)
+			}
+			if plan.FreeMinutes != tt.freeMinutes || plan.TotalMinutes != tt.wantTotal || plan.LeftoverMinutes != tt.freeMinutes-tt.wantTotal {
+				t.Errorf("got free %d, total %d, leftover %d, want %d, %d, %d",
+					plan.FreeMinutes, plan.TotalMinutes, plan.LeftoverMinutes, tt.freeMinutes, tt.wantTotal, tt.freeMinutes-tt.wantTotal)
+			}
+			start := 0
+			for _, item := range plan.Items {
+				if item.StartMinute != start {
+					t.Errorf("item %d starts at %d, want %d", item.ID, item.StartMinute, start)
+				}
+				start += item.Minutes
+			}
+		})
+	}
+}
+
+func TestPlanValue(t *testing.T) {
+	site := planSite(1, 10)
+	tests := []struct {
+		name     string
+		tsundoku domain.Tsundoku
+		want     float64
+	}{
+		{"normal", site, 2},
+		{"lowest priority", withPriority(site, domain.PriorityLowest), 1},
+		{"highest priority", withPriority(site, domain.PriorityHighest), 3},
+		{"overdue", withDeadline(site, planNow.AddDate(0, 0, -1)), 5},
+		{"deadline in a week", withDeadline(site, planNow.AddDate(0, 0, 7)), 3.5},
+		{"deadline in two weeks", withDeadline(site, planNow.AddDate(0, 0, 14)), 2.75},
+		{"piled for 45 days", domain.Tsundoku{Priority: domain.PriorityNormal, CreatedAt: planNow.AddDate(0, 0, -45)}, 2.5},
+		{"piled for a year", domain.Tsundoku{Priority: domain.PriorityNormal, CreatedAt: planNow.AddDate(-1, 0, 0)}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := planValue(tt.tsundoku, planNow); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBookChunk(t *testing.T) {
+	tests := []struct {
+		name           string
+		remainingPages int
+		freeMinutes    int
+		pagesPerMinute float64
+		wantPages      int
+		wantMinutes    int
+	}{
+		{"capped at a chunk", 300, 45, 0.5, 15, 30},
+		{"shorter than a chunk", 300, 10, 0.5, 5, 10},
+		{"few pages left", 4, 45, 0.5, 4, 8},
+		{"zero-minute budget", 300, 0, 0.5, 0, 0},
+		{"too slow to read a page", 300, 30, 0.01, 0, 0},
+		// 4.5ページ読める時間なら4ページ読む。4ページにかかるのは9分
+		{"rounding down pages", 300, 10, 0.45, 4, 9},
+		// 21/0.7は30.000...4なので、切り上げても空き時間を超えないようにする
+		{"rounding stays within minutes", 300, 30, 0.7, 21, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages, minutes := bookChunk(tt.remainingPages, tt.freeMinutes, tt.pagesPerMinute)
+			if pages != tt.wantPages || minutes != tt.wantMinutes {
+				t.Errorf("got %d pages in %d minutes, want %d pages in %d minutes", pages, minutes, tt.wantPages, tt.wantMinutes)
+			}
+		})
+	}
+}
+
+func TestPlanFreeTimeBooks(t *testing.T) {
+	book := func(id, currentPage, totalPages, requiredMinutes int) domain.Tsundoku {
+		return domain.Tsundoku{ID: id, Category: domain.CategoryBook, CurrentPage: currentPage, TotalPages: totalPages,
+			RequiredMinutes: requiredMinutes, Priority: domain.PriorityNormal, CreatedAt: planNow}
+	}
+	tests := []struct {
+		name        string
+		tsundoku    domain.Tsundoku
+		freeMinutes int
+		want        *domain.PlanItem // nilなら計画に入らない
+	}{
+		{"reads a chunk", book(1, 20, 120, 0), 45, &domain.PlanItem{Minutes: 30, Pages: 15, EndPage: 35}},
+		{"reads to the end", book(1, 116, 120, 0), 45, &domain.PlanItem{Minutes: 8, Pages: 4, EndPage: 120}},
+		// ページ数の分からない本は読む時間で計画する
+		{"unknown pages", book(1, 0, 0, 20), 45, &domain.PlanItem{Minutes: 20}},
+		{"unknown pages and time", book(1, 0, 0, 0), 45, nil},
+		{"finished", book(1, 120, 120, 0), 45, nil},
+		{"zero-minute budget", book(1, 20, 120, 0), 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanFreeTime([]domain.Tsundoku{tt.tsundoku}, tt.freeMinutes, domain.DefaultPagesPerMinute, planNow)
+			if tt.want == nil {
+				if len(plan.Items) != 0 {
+					t.Errorf("got %d items, want none", len(plan.Items))
+				}
+				return
+			}
+			if len(plan.Items) != 1 {
+				t.Fatalf("got %d items, want 1", len(plan.Items))
+			}
+			got := plan.Items[0]
+			if got.Minutes != tt.want.Minutes || got.Pages != tt.want.Pages || got.EndPage != tt.want.EndPage {
+				t.Errorf("got %d minutes, %d pages to page %d, want %d minutes, %d pages to page %d",
+					got.Minutes, got.Pages, got.EndPage, tt.want.Minutes, tt.want.Pages, tt.want.EndPage)
+			}
+		})
+	}
+}
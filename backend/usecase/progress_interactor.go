@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// 本をどこまで読んだか記録する
+type ProgressInteractor struct {
+	TsundokuRepository TsundokuRepository
+	ProgressRepository ProgressRepository
+}
+
+// pageまで読んだことを記録する。minutesは読んでいた時間で、0なら不明
+// 読み進めたら読書中にし、最後のページまで読んだら読み終わりにする
+func (interactor *ProgressInteractor) Record(userID, tsundokuID, page, minutes int) (domain.Progress, error) {
+	tsundoku, err := ownedTsundoku(interactor.TsundokuRepository, userID, tsundokuID)
+	if err != nil {
+		return domain.Progress{}, err
+	}
+	if tsundoku.Category != domain.CategoryBook {
+		return domain.Progress{}, domain.ValidationError("progress can only be recorded for books")
+	}
+	if page < 0 || (tsundoku.TotalPages > 0 && page > tsundoku.TotalPages) {
+		return domain.Progress{}, domain.ValidationError("page must be between 0 and totalPages")
+	}
+	if minutes < 0 || minutes > domain.MaxRequiredMinutes {
+		return domain.Progress{}, domain.ValidationError("minutes is out of range")
+	}
+
+	now := time.Now()
+	log := domain.ProgressLog{
+		UserID:     userID,
+		TsundokuID: tsundokuID,
+		Page:       page,
+		PagesRead:  page - tsundoku.CurrentPage,
+		Minutes:    minutes,
+	}
+	if page > tsundoku.CurrentPage && (tsundoku.Status == domain.StatusUnread || tsundoku.Status == domain.StatusAbandoned) {
+		if err := tsundoku.ChangeStatus(domain.StatusReading, now); err != nil {
+			return domain.Progress{}, err
+		}
+	}
+	if tsundoku.TotalPages > 0 && page == tsundoku.TotalPages && tsundoku.Status == domain.StatusReading {
+		if err := tsundoku.ChangeStatus(domain.StatusDone, now); err != nil {
+			return domain.Progress{}, err
+		}
+	}
+	tsundoku.CurrentPage = page
+	if _, err := interactor.ProgressRepository.Store(tsundoku, log); err != nil {
+		return domain.Progress{}, err
+	}
+	return interactor.Get(userID, tsundokuID)
+}
+
+// 本の読み進め具合と記録を取得する
+func (interactor *ProgressInteractor) Get(userID, tsundokuID int) (domain.Progress, error) {
+	tsundoku, err := ownedTsundoku(interactor.TsundokuRepository, userID, tsundokuID)
+	if err != nil {
+		return domain.Progress{}, err
+	}
+	logs, err := interactor.ProgressRepository.SelectByTsundoku(tsundokuID)
+	if err != nil {
+		return domain.Progress{}, err
+	}
+	return domain.Progress{
+		TsundokuID:      tsundoku.ID,
+		CurrentPage:     tsundoku.CurrentPage,
+		TotalPages:      tsundoku.TotalPages,
+		PercentComplete: tsundoku.PercentComplete,
+		Logs:            logs,
+	}, nil
+}
@@ -0,0 +1,11 @@
+package usecase
+
+import "github.com/yot-sailing/TSUNTSUN/domain"
+
+type ProgressRepository interface {
+	// 記録を保存し、積読のページと読書状態を更新する
+	Store(tsundoku domain.Tsundoku, log domain.ProgressLog) (domain.ProgressLog, error)
+	SelectByTsundoku(tsundokuID int) ([]domain.ProgressLog, error)
+	// 読んだ時間の分かる記録から、ユーザーの読む速さを測る
+	SelectPace(userID int) (domain.ReadingPace, error)
+}
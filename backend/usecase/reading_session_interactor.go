@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// 積読を読んだ時間を測り、ユーザーの読む速さを学ぶ
+type ReadingSessionInteractor struct {
+	TsundokuRepository       TsundokuRepository
+	ReadingSessionRepository ReadingSessionRepository
+	ProgressRepository       ProgressRepository
+	UserRepository           UserRepository
+	Speeds                   ReadingSpeeds
+	// 書き込みを一つのトランザクションにまとめる。fnには同じトランザクションのリポジトリを使うインタラクターを渡す
+	// nilならそのままfnを呼ぶ
+	Transaction func(fn func(tx *ReadingSessionInteractor) error) error
+}
+
+// 読み終わったときに受け取る値
+type ReadingSessionStop struct {
+	Page     *int // 本ならどのページまで読んだか
+	Finished bool // 読み終わったか
+}
+
+// 積読を読み始めてタイマーを動かす。タイマーは一人一つで、読み終わっていない積読は読書中にする
+func (interactor *ReadingSessionInteractor) Start(userID, tsundokuID int) (domain.ReadingSession, error) {
+	tsundoku, err := ownedTsundoku(interactor.TsundokuRepository, userID, tsundokuID)
+	if err != nil {
+		return domain.ReadingSession{}, err
+	}
+	if tsundoku.Status == domain.StatusDone {
+		return domain.ReadingSession{}, domain.ValidationError("tsundoku is already done")
+	}
+	now := time.Now()
+	var session domain.ReadingSession
+	err = interactor.transaction(func(tx *ReadingSessionInteractor) error {
+		var err error
+		session, err = tx.ReadingSessionRepository.Store(domain.ReadingSession{
+			UserID:           userID,
+			TsundokuID:       tsundokuID,
+			StartedAt:        now,
+			EstimatedMinutes: tsundoku.RequiredMinutes,
+		})
+		if errors.Is(err, domain.ErrConflict) {
+			return domain.ConflictError("another reading session is running")
+		}
+		if err != nil {
+			return err
+		}
+		if tsundoku.Status == domain.StatusReading {
+			return nil
+		}
+		if err := tsundoku.ChangeStatus(domain.StatusReading, now); err != nil {
+			return err
+		}
+		return tx.TsundokuRepository.Update(tsundoku)
+	})
+	if err != nil {
+		return domain.ReadingSession{}, err
+	}
+	return session, nil
+}
+
+// タイマーを止めて読んだ時間を記録し、学んだ速さで見積もりを更新する
+func (interactor *ReadingSessionInteractor) Stop(userID, tsundokuID int, stop ReadingSessionStop) (domain.ReadingSession, error) {
+	tsundoku, err := ownedTsundoku(interactor.TsundokuRepository, userID, tsundokuID)
+	if err != nil {
+		return domain.ReadingSession{}, err
+	}
+	session, err := interactor.ReadingSessionRepository.SelectRunning(userID)
+	if err == nil && session.TsundokuID != tsundokuID {
+		err = domain.ErrNotFound
+	}
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.ReadingSession{}, domain.NotFoundError("no reading session is running for this tsundoku")
+	}
+	if err != nil {
+		return domain.ReadingSession{}, err
+	}
+
+	now := time.Now()
+	session.EndedAt = &now
+	session.Minutes = int(math.Min(math.Round(now.Sub(session.StartedAt).Minutes()), domain.MaxSessionMinutes))
+	// 進み具合、読書状態、タイマー、見積もりのどれかだけが変わることのないようにする
+	err = interactor.transaction(func(tx *ReadingSessionInteractor) error {
+		if stop.Page != nil {
+			progress := ProgressInteractor{TsundokuRepository: tx.TsundokuRepository, ProgressRepository: tx.ProgressRepository}
+			if _, err := progress.Record(userID, tsundokuID, *stop.Page, session.Minutes); err != nil {
+				return err
+			}
+			if *stop.Page > tsundoku.CurrentPage {
+				session.Pages = *stop.Page - tsundoku.CurrentPage
+			}
+			var err error
+			if tsundoku, err = tx.TsundokuRepository.SelectByID(tsundokuID); err != nil {
+				return err
+			}
+		}
+		if stop.Finished && tsundoku.Status != domain.StatusDone {
+			if err := tsundoku.ChangeStatus(domain.StatusDone, now); err != nil {
+				return err
+			}
+			if err := tx.TsundokuRepository.Update(tsundoku); err != nil {
+				return err
+			}
+		}
+		if err := tx.ReadingSessionRepository.Update(session); err != nil {
+			return err
+		}
+
+		speeds, err := userSpeeds(tx.UserRepository, tx.ReadingSessionRepository, tx.Speeds, userID)
+		if err != nil {
+			return err
+		}
+		return tx.ReadingSessionRepository.UpdateEstimates(userID, speeds)
+	})
+	if err != nil {
+		return domain.ReadingSession{}, err
+	}
+	return session, nil
+}
+
+func (interactor *ReadingSessionInteractor) transaction(fn func(tx *ReadingSessionInteractor) error) error {
+	if interactor.Transaction == nil {
+		return fn(interactor)
+	}
+	return interactor.Transaction(fn)
+}
+
+func (interactor *ReadingSessionInteractor) GetInfoByTsundoku(userID, tsundokuID int) ([]domain.ReadingSession, error) {
+	if _, err := ownedTsundoku(interactor.TsundokuRepository, userID, tsundokuID); err != nil {
+		return nil, err
+	}
+	return interactor.ReadingSessionRepository.SelectByTsundoku(tsundokuID)
+}
+
+// 学んだ読む速さと、見積もりと実際に読んだ時間の比較
+func (interactor *ReadingSessionInteractor) Report(userID int) (domain.ReadingSpeedReport, error) {
+	samples, err := interactor.ReadingSessionRepository.SelectSamples(userID)
+	if err != nil {
+		return domain.ReadingSpeedReport{}, err
+	}
+	return domain.ReadingSpeedReport{
+		Speeds:   LearnSpeeds(samples),
+		Accuracy: MeasureAccuracy(samples),
+	}, nil
+}
+
+// ユーザーがサイトを読む速さ
+func userSpeeds(users UserRepository, sessions ReadingSessionRepository, defaults ReadingSpeeds, userID int) (ReadingSpeeds, error) {
+	user, err := users.SelectByID(userID)
+	if err != nil {
+		return ReadingSpeeds{}, err
+	}
+	samples, err := sessions.SelectSamples(userID)
+	if err != nil {
+		return ReadingSpeeds{}, err
+	}
+	return personalSpeeds(user, LearnSpeeds(samples), defaults), nil
+}
@@ -0,0 +1,16 @@
+package usecase
+
+import "github.com/yot-sailing/TSUNTSUN/domain"
+
+type ReadingSessionRepository interface {
+	// ユーザーがすでにタイマーを動かしていればdomain.ErrConflict
+	Store(session domain.ReadingSession) (domain.ReadingSession, error)
+	// タイマーの動いている読書。なければdomain.ErrNotFound
+	SelectRunning(userID int) (domain.ReadingSession, error)
+	SelectByTsundoku(tsundokuID int) ([]domain.ReadingSession, error)
+	Update(session domain.ReadingSession) error
+	// 終わった読書を積読ごとにまとめる
+	SelectSamples(userID int) ([]domain.ReadingSample, error)
+	// 見積もったまま読み終わっていないサイトの読む時間を、speedsで見積もり直す
+	UpdateEstimates(userID int, speeds ReadingSpeeds) error
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"math"
+	"sort"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// 読書の記録から、カテゴリと言語ごとの読む速さを学ぶ
+// サイトは読み終わったものだけを使い、日本語なら文字数、英語なら単語数を読んだ時間で割る
+// 本は読み進めたページ数を読んだ時間で割る
+func LearnSpeeds(samples []domain.ReadingSample) []domain.LearnedSpeed {
+	type key struct{ category, language string }
+	totals := map[key]*domain.LearnedSpeed{}
+	units := map[key]int{}
+	for _, sample := range samples {
+		if sample.Minutes <= 0 {
+			continue
+		}
+		k := key{sample.Category, sample.Language}
+		amount, unit := 0, ""
+		switch {
+		case sample.Category == domain.CategoryBook && sample.Pages > 0:
+			amount, unit = sample.Pages, domain.SpeedUnitPages
+		case sample.Category == domain.CategorySite && sample.Status == domain.StatusDone && sample.Language == domain.LanguageJapanese:
+			amount, unit = sample.TextChars, domain.SpeedUnitChars
+		case sample.Category == domain.CategorySite && sample.Status == domain.StatusDone && sample.Language == domain.LanguageEnglish:
+			amount, unit = sample.TextWords, domain.SpeedUnitWords
+		}
+		if amount <= 0 {
+			continue
+		}
+		if totals[k] == nil {
+			totals[k] = &domain.LearnedSpeed{Category: k.category, Language: k.language, Unit: unit}
+		}
+		totals[k].Samples++
+		totals[k].Minutes += sample.Minutes
+		units[k] += amount
+	}
+
+	speeds := []domain.LearnedSpeed{}
+	for k, speed := range totals {
+		speed.PerMinute = math.Round(float64(units[k])/float64(speed.Minutes)*100) / 100
+		speed.Applied = speed.Minutes >= domain.MinLearnedSpeedMinutes
+		speeds = append(speeds, *speed)
+	}
+	sort.Slice(speeds, func(i, j int) bool {
+		if speeds[i].Category != speeds[j].Category {
+			return speeds[i].Category < speeds[j].Category
+		}
+		return speeds[i].Language < speeds[j].Language
+	})
+	return speeds
+}
+
+// ユーザーがサイトを読む速さ。ユーザーが設定した速さ、記録から学んだ速さ、defaultsの順に使う
+func personalSpeeds(user domain.User, learned []domain.LearnedSpeed, defaults ReadingSpeeds) ReadingSpeeds {
+	speeds := defaults
+	for _, speed := range learned {
+		if speed.Category != domain.CategorySite || !speed.Applied {
+			continue
+		}
+		switch speed.Unit {
+		case domain.SpeedUnitChars:
+			speeds.CharsPerMinute = int(math.Max(1, math.Round(speed.PerMinute)))
+		case domain.SpeedUnitWords:
+			speeds.WordsPerMinute = int(math.Max(1, math.Round(speed.PerMinute)))
+		}
+	}
+	if user.ReadingSpeed > 0 {
+		speeds.CharsPerMinute = user.ReadingSpeed
+	}
+	return speeds.orDefault()
+}
+
+// 読み終わった積読について、最初に読み始めたときの見積もりと実際に読んだ時間を比べる
+func MeasureAccuracy(samples []domain.ReadingSample) domain.EstimateAccuracy {
+	accuracy := domain.EstimateAccuracy{Items: []domain.EstimateComparison{}}
+	totalError := 0.0
+	for _, sample := range samples {
+		if sample.Status != domain.StatusDone || sample.EstimatedMinutes <= 0 || sample.Minutes <= 0 {
+			continue
+		}
+		errorPercent := float64(sample.EstimatedMinutes-sample.Minutes) / float64(sample.Minutes) * 100
+		accuracy.Items = append(accuracy.Items, domain.EstimateComparison{
+			TsundokuID:       sample.TsundokuID,
+			Title:            sample.Title,
+			Category:         sample.Category,
+			EstimatedMinutes: sample.EstimatedMinutes,
+			ActualMinutes:    sample.Minutes,
+			ErrorPercent:     math.Round(errorPercent*10) / 10,
+		})
+		totalError += math.Abs(errorPercent)
+	}
+	accuracy.Samples = len(accuracy.Items)
+	if accuracy.Samples > 0 {
+		accuracy.MeanAbsolutePercentError = math.Round(totalError/float64(accuracy.Samples)*10) / 10
+	}
+	return accuracy
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+func japaneseSite(chars, minutes int) domain.ReadingSample {
+	return domain.ReadingSample{Category: domain.CategorySite, Language: domain.LanguageJapanese, Status: domain.StatusDone, TextChars: chars, Minutes: minutes}
+}
+
+func TestLearnSpeeds(t *testing.T) {
+	englishSite := domain.ReadingSample{Category: domain.CategorySite, Language: domain.LanguageEnglish, Status: domain.StatusDone, TextWords: 4000, Minutes: 20}
+	reading := japaneseSite(6000, 20)
+	reading.Status = domain.StatusReading
+	book := domain.ReadingSample{Category: domain.CategoryBook, Language: domain.LanguageJapanese, Status: domain.StatusReading, Pages: 20, Minutes: 40}
+	noPages := book
+	noPages.Pages = 0
+
+	tests := []struct {
+		name    string
+		samples []domain.ReadingSample
+		want    []domain.LearnedSpeed
+	}{
+		{"no sessions", nil, []domain.LearnedSpeed{}},
+		{"no time recorded", []domain.ReadingSample{japaneseSite(6000, 0)}, []domain.LearnedSpeed{}},
+		{"unfinished site", []domain.ReadingSample{reading}, []domain.LearnedSpeed{}},
+		{"book without pages", []domain.ReadingSample{noPages}, []domain.LearnedSpeed{}},
+		// MinLearnedSpeedMinutesに満たないうちは学んでも使わない
+		{"too short to apply", []domain.ReadingSample{japaneseSite(6000, 20)}, []domain.LearnedSpeed{
+			{Category: domain.CategorySite, Language: domain.LanguageJapanese, Unit: domain.SpeedUnitChars, PerMinute: 300, Samples: 1, Minutes: 20},
+		}},
+		{"sessions add up", []domain.ReadingSample{japaneseSite(6000, 20), japaneseSite(4000, 10)}, []domain.LearnedSpeed{
+			{Category: domain.CategorySite, Language: domain.LanguageJapanese, Unit: domain.SpeedUnitChars, PerMinute: 333.33, Samples: 2, Minutes: 30, Applied: true},
+		}},
+		// 読みかけのサイトは文章のどこまで読んだか分からないので数えない
+		{"unfinished site ignored", []domain.ReadingSample{japaneseSite(6000, 30), reading}, []domain.LearnedSpeed{
+			{Category: domain.CategorySite, Language: domain.LanguageJapanese, Unit: domain.SpeedUnitChars, PerMinute: 200, Samples: 1, Minutes: 30, Applied: true},
+		}},
+		{"per category and language", []domain.ReadingSample{japaneseSite(6000, 20), englishSite, book}, []domain.LearnedSpeed{
+			{Category: domain.CategoryBook, Language: domain.LanguageJapanese, Unit: domain.SpeedUnitPages, PerMinute: 0.5, Samples: 1, Minutes: 40, Applied: true},
+			{Category: domain.CategorySite, Language: domain.LanguageEnglish, Unit: domain.SpeedUnitWords, PerMinute: 200, Samples: 1, Minutes: 20},
+			{Category: domain.CategorySite, Language: domain.LanguageJapanese, Unit: domain.SpeedUnitChars, PerMinute: 300, Samples: 1, Minutes: 20},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LearnSpeeds(tt.samples); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPersonalSpeeds(t *testing.T) {
+	site := func(unit string, perMinute float64, applied bool) domain.LearnedSpeed {
+		return domain.LearnedSpeed{Category: domain.CategorySite, Unit: unit, PerMinute: perMinute, Applied: applied}
+	}
+	configured := ReadingSpeeds{CharsPerMinute: 800, WordsPerMinute: 250}
+	tests := []struct {
+		name     string
+		user     domain.User
+		learned  []domain.LearnedSpeed
+		defaults ReadingSpeeds
+		want     ReadingSpeeds
+	}{
+		{"first session", domain.User{}, nil, ReadingSpeeds{}, ReadingSpeeds{CharsPerMinute: domain.DefaultReadingSpeed, WordsPerMinute: domain.DefaultWordsPerMinute}},
+		{"first session with configured defaults", domain.User{}, nil, configured, configured},
+		{"not applied yet", domain.User{}, []domain.LearnedSpeed{site(domain.SpeedUnitChars, 300, false)}, configured, configured},
+		{"learned chars", domain.User{}, []domain.LearnedSpeed{site(domain.SpeedUnitChars, 333.33, true)}, configured, ReadingSpeeds{CharsPerMinute: 333, WordsPerMinute: 250}},
+		{"learned words", domain.User{}, []domain.LearnedSpeed{site(domain.SpeedUnitWords, 180.5, true)}, configured, ReadingSpeeds{CharsPerMinute: 800, WordsPerMinute: 181}},
+		{"very slow", domain.User{}, []domain.LearnedSpeed{site(domain.SpeedUnitChars, 0.2, true)}, configured, ReadingSpeeds{CharsPerMinute: 1, WordsPerMinute: 250}},
+		{"book speed ignored", domain.User{}, []domain.LearnedSpeed{{Category: domain.CategoryBook, Unit: domain.SpeedUnitPages, PerMinute: 0.5, Applied: true}}, configured, configured},
+		// ユーザーが設定した速さは学んだ速さより優先する。設定は文字数だけなので単語数は学んだもの
+		{"user setting overrides", domain.User{ReadingSpeed: 600}, []domain.LearnedSpeed{site(domain.SpeedUnitChars, 300, true), site(domain.SpeedUnitWords, 180, true)}, configured, ReadingSpeeds{CharsPerMinute: 600, WordsPerMinute: 180}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := personalSpeeds(tt.user, tt.learned, tt.defaults); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// 読書を記録するたびに学んだ速さが見積もりに使う速さに反映される
+func TestLearnedSpeedUpdates(t *testing.T) {
+	sessions := []struct {
+		sample domain.ReadingSample
+		want   int // 記録したあとに使う1分間の文字数
+	}{
+		{japaneseSite(6000, 20), domain.DefaultReadingSpeed}, // 20分ではまだ使わない
+		{japaneseSite(9000, 20), 375},                        // 15000文字を40分
+		{japaneseSite(12000, 20), 450},                       // 27000文字を60分
+		{japaneseSite(0, 20), 450},                           // 文字数の分からないページは数えない
+	}
+	samples := []domain.ReadingSample{}
+	for i, session := range sessions {
+		samples = append(samples, session.sample)
+		if got := personalSpeeds(domain.User{}, LearnSpeeds(samples), ReadingSpeeds{}); got.CharsPerMinute != session.want {
+			t.Errorf("after session %d: got %d chars per minute, want %d", i+1, got.CharsPerMinute, session.want)
+		}
+	}
+}
+
+func TestMeasureAccuracy(t *testing.T) {
+	done := func(id, estimated, actual int) domain.ReadingSample {
+		return domain.ReadingSample{TsundokuID: id, Category: domain.CategorySite, Status: domain.StatusDone, EstimatedMinutes: estimated, Minutes: actual}
+	}
+	unfinished := done(3, 30, 20)
+	unfinished.Status = domain.StatusReading
+	tests := []struct {
+		name    string
+		samples []domain.ReadingSample
+		want    domain.EstimateAccuracy
+	}{
+		{"no sessions", nil, domain.EstimateAccuracy{Items: []domain.EstimateComparison{}}},
+		{"skips unfinished and unestimated", []domain.ReadingSample{unfinished, done(4, 0, 20), done(5, 30, 0)}, domain.EstimateAccuracy{Items: []domain.EstimateComparison{}}},
+		{"over and under", []domain.ReadingSample{done(1, 30, 20), done(2, 10, 20)}, domain.EstimateAccuracy{
+			Samples:                  2,
+			MeanAbsolutePercentError: 50,
+			Items: []domain.EstimateComparison{
+				{TsundokuID: 1, Category: domain.CategorySite, EstimatedMinutes: 30, ActualMinutes: 20, ErrorPercent: 50},
+				{TsundokuID: 2, Category: domain.CategorySite, EstimatedMinutes: 10, ActualMinutes: 20, ErrorPercent: -50},
+			},
+		}},
+		{"rounded", []domain.ReadingSample{done(1, 10, 30)}, domain.EstimateAccuracy{
+			Samples:                  1,
+			MeanAbsolutePercentError: 66.7,
+			Items: []domain.EstimateComparison{
+				{TsundokuID: 1, Category: domain.CategorySite, EstimatedMinutes: 10, ActualMinutes: 30, ErrorPercent: -66.7},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MeasureAccuracy(tt.samples); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"math"
+	"unicode"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// 読む速さ
+type ReadingSpeeds struct {
+	CharsPerMinute int // 日本語など、単語を空白で区切らない文章を1分間に読む文字数
+	WordsPerMinute int // 英語など、1分間に読む単語数
+}
+
+// 0の項目をデフォルトの速さにする
+func (speeds ReadingSpeeds) orDefault() ReadingSpeeds {
+	if speeds.CharsPerMinute <= 0 {
+		speeds.CharsPerMinute = domain.DefaultReadingSpeed
+	}
+	if speeds.WordsPerMinute <= 0 {
+		speeds.WordsPerMinute = domain.DefaultWordsPerMinute
+	}
+	return speeds
+}
+
+// 文章の長さ。漢字とかなは文字数、それ以外は単語数で数える
+type TextLength struct {
+	Chars int
+	Words int
+}
+
+func MeasureText(text string) TextLength {
+	length := TextLength{}
+	inWord := false
+	for _, r := range text {
+		switch {
+		// 長音符(ー)はカタカナに含まれないので別に数える
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == 'ー':
+			length.Chars++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				length.Words++
+			}
+			inWord = true
+		case r == '\'' || r == '’' || r == '-':
+			// don'tやwell-knownは一つの単語
+		default:
+			inWord = false
+		}
+	}
+	return length
+}
+
+// 文字数が単語数より多ければ日本語の文章とする
+func (length TextLength) Language() string {
+	if length.Chars > length.Words {
+		return domain.LanguageJapanese
+	}
+	return domain.LanguageEnglish
+}
+
+// 読むのにかかる分数。文章がなければ0、少しでもあれば1分以上
+func (length TextLength) Minutes(speeds ReadingSpeeds) int {
+	speeds = speeds.orDefault()
+	if length.Chars == 0 && length.Words == 0 {
+		return 0
+	}
+	minutes := float64(length.Chars)/float64(speeds.CharsPerMinute) + float64(length.Words)/float64(speeds.WordsPerMinute)
+	return int(math.Max(1, math.Round(minutes)))
+}
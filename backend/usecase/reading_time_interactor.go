@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+const (
+	// 一度に見積もる積読の数
+	estimateBatchSize = 20
+	// 見積もりを試す回数
+	maxEstimateAttempts = 3
+	// 失敗してから試し直すまでの間隔
+	estimateRetryInterval = 10 * time.Minute
+)
+
+// サイトの積読の読む時間を、ページの本文から見積もる
+// 読む速さはユーザーごとに、設定か読書の記録から学んだものを使う。なければSpeeds
+type ReadingTimeInteractor struct {
+	ReadingTimeRepository    ReadingTimeRepository
+	ReadingSessionRepository ReadingSessionRepository
+	UserRepository           UserRepository
+	PageFetcher              PageFetcher
+	Speeds                   ReadingSpeeds
+}
+
+// まだ見積もっていない積読を見積もる。ページの取得に失敗したものは後で試し直す
+func (interactor *ReadingTimeInteractor) EstimatePending() error {
+	now := time.Now()
+	tsundokus, err := interactor.ReadingTimeRepository.SelectPendingEstimates(maxEstimateAttempts, now.Add(-estimateRetryInterval), estimateBatchSize)
+	if err != nil {
+		return err
+	}
+	speeds := map[int]ReadingSpeeds{}
+	for _, tsundoku := range tsundokus {
+		if _, ok := speeds[tsundoku.UserID]; !ok {
+			userSpeeds, err := userSpeeds(interactor.UserRepository, interactor.ReadingSessionRepository, interactor.Speeds, tsundoku.UserID)
+			if err != nil {
+				return err
+			}
+			speeds[tsundoku.UserID] = userSpeeds
+		}
+		if err := interactor.estimate(tsundoku, speeds[tsundoku.UserID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (interactor *ReadingTimeInteractor) estimate(tsundoku domain.Tsundoku, speeds ReadingSpeeds) error {
+	now := time.Now()
+	text, err := interactor.PageFetcher.FetchText(tsundoku.URL)
+	if err != nil {
+		attempts := tsundoku.EstimateAttempts + 1
+		// 取得し直しても変わらないものは諦める
+		if !errors.Is(err, domain.ErrInternal) {
+			attempts = maxEstimateAttempts
+		}
+		return interactor.ReadingTimeRepository.RecordEstimateFailure(tsundoku.ID, attempts, now)
+	}
+
+	length := MeasureText(text)
+	minutes := length.Minutes(speeds)
+	if minutes == 0 {
+		return interactor.ReadingTimeRepository.RecordEstimateFailure(tsundoku.ID, maxEstimateAttempts, now)
+	}
+	return interactor.ReadingTimeRepository.StoreEstimate(tsundoku, length, minutes, now)
+}
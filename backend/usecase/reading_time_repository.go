@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type ReadingTimeRepository interface {
+	// 読む時間をまだ見積もっていないサイトの積読を取得する
+	// 失敗した回数がmaxAttempts未満で、最後に試したのがretryBeforeより前のもの
+	SelectPendingEstimates(maxAttempts int, retryBefore time.Time, limit int) ([]domain.Tsundoku, error)
+	// 見積もった時間と本文の長さを保存する。そのあいだにユーザーが時間を入力したりURLを変えたりしていたら保存しない
+	// 言語はユーザーが決めていなければ本文から決める
+	StoreEstimate(tsundoku domain.Tsundoku, length TextLength, minutes int, checkedAt time.Time) error
+	RecordEstimateFailure(id int, attempts int, checkedAt time.Time) error
+}
+
+// ページの本文を取得する
+type PageFetcher interface {
+	// ページを取得して本文の文字列を返す
+	// 取得し直しても無駄なとき(404やHTMLでないなど)はdomain.ErrInternal以外のエラーを返す
+	FetchText(url string) (string, error)
+}
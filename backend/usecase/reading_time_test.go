@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+func TestMeasureText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		want     TextLength
+		language string
+	}{
+		{"empty", "", TextLength{}, domain.LanguageEnglish},
+		{"japanese", "積読を読む。", TextLength{Chars: 5}, domain.LanguageJapanese},
+		{"katakana with long vowel mark", "ツンドクリーダー", TextLength{Chars: 8}, domain.LanguageJapanese},
+		{"english", "Don't pile up well-known books.", TextLength{Words: 5}, domain.LanguageEnglish},
+		{"numbers are words", "Go 1.16", TextLength{Words: 3}, domain.LanguageEnglish},
+		{"mixed", "Goで書いたAPIサーバー", TextLength{Chars: 8, Words: 2}, domain.LanguageJapanese},
+		{"punctuation only", "……！？", TextLength{}, domain.LanguageEnglish},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MeasureText(tt.text)
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if language := got.Language(); language != tt.language {
+				t.Errorf("language = %q, want %q", language, tt.language)
+			}
+		})
+	}
+}
+
+func TestTextLengthMinutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		length TextLength
+		speeds ReadingSpeeds
+		want   int
+	}{
+		{"no text", TextLength{}, ReadingSpeeds{}, 0},
+		{"short text is at least a minute", TextLength{Chars: 10}, ReadingSpeeds{}, 1},
+		{"default japanese speed", TextLength{Chars: 5000}, ReadingSpeeds{}, 10},
+		{"default english speed", TextLength{Words: 1000}, ReadingSpeeds{}, 5},
+		{"mixed text adds both", TextLength{Chars: 2500, Words: 400}, ReadingSpeeds{}, 7},
+		{"rounds to nearest minute", TextLength{Chars: 1249}, ReadingSpeeds{}, 2},
+		{"custom speeds", TextLength{Chars: 3000, Words: 600}, ReadingSpeeds{CharsPerMinute: 1000, WordsPerMinute: 300}, 5},
+		{"zero speed falls back to default", TextLength{Words: 400}, ReadingSpeeds{WordsPerMinute: 0}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.length.Minutes(tt.speeds); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+const (
+	// おすすめの数
+	DefaultRecommendationCount = 3
+	MaxRecommendationCount     = 20
+	// この期間に読み始めたか読み終わった積読を最近の読書とする
+	recentActivityPeriod = 14 * 24 * time.Hour
+	// 日替わりで加える点数の最大。同じ積読ばかりおすすめしないように、他の点数の差を入れ替えられるくらいにする
+	rotationWeight = 2.0
+)
+
+// 今日のおすすめを選ぶ
+type RecommendationInteractor struct {
+	TsundokuRepository TsundokuRepository
+	UserRepository     UserRepository
+}
+
+// 読み終わっていない積読から点数の高いものをcount件返す。categoriesで絞り込める
+// 同じ日のうちは同じ結果になり、日が変わると入れ替わる。日付はユーザーのタイムゾーンで数える
+func (interactor *RecommendationInteractor) Recommend(userID int, count int, categories []string) ([]domain.Recommendation, error) {
+	if count == 0 {
+		count = DefaultRecommendationCount
+	}
+	if count < 0 || count > MaxRecommendationCount {
+		return nil, domain.ValidationError("count must be between 1 and " + strconv.Itoa(MaxRecommendationCount))
+	}
+	user, err := interactor.UserRepository.SelectByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	// 最近の読書も見るので、読み終わったものも含めてすべて取得する
+	tsundokus := TsundokuInteractor{TsundokuRepository: interactor.TsundokuRepository}
+	all, _, err := tsundokus.GetInfoByFilter(userID, TsundokuFilter{}, Page{})
+	if err != nil {
+		return nil, err
+	}
+	candidates := []domain.Tsundoku{}
+	for _, tsundoku := range all {
+		if tsundoku.Status != domain.StatusUnread && tsundoku.Status != domain.StatusReading {
+			continue
+		}
+		if len(categories) > 0 && !containsString(categories, tsundoku.Category) {
+			continue
+		}
+		candidates = append(candidates, tsundoku)
+	}
+	return RecommendTsundokus(candidates, all, userID, count, time.Now().In(location)), nil
+}
+
+// candidatesに点数をつけて高い順にcount件返す。historyは最近の読書を調べる積読
+func RecommendTsundokus(candidates, history []domain.Tsundoku, userID int, count int, now time.Time) []domain.Recommendation {
+	recentTags := recentTagNames(history, now)
+	day := now.Format("2006-01-02")
+
+	recommendations := make([]domain.Recommendation, len(candidates))
+	for i, tsundoku := range candidates {
+		recommendation := domain.Recommendation{Tsundoku: tsundoku, Reasons: []string{}}
+		score := 0.0
+		if urgency := deadlineUrgency(tsundoku, now); urgency > 0 {
+			score += urgency
+			// 2週間以内
+			if urgency >= 0.75 {
+				recommendation.Reasons = append(recommendation.Reasons, domain.ReasonDeadline)
+			}
+		}
+		stale := staleness(tsundoku, now)
+		score += stale
+		if stale >= 1.0/3 {
+			recommendation.Reasons = append(recommendation.Reasons, domain.ReasonStale)
+		}
+		// 短いものほど気軽に読める。30分で0.5
+		if tsundoku.RequiredMinutes > 0 {
+			score += 1 / (1 + float64(tsundoku.RequiredMinutes)/30)
+			if tsundoku.RequiredMinutes <= 15 {
+				recommendation.Reasons = append(recommendation.Reasons, domain.ReasonQuick)
+			}
+		}
+		// 最近読んだ積読と同じタグが多いほど大きい。最大1
+		related := 0
+		for _, tag := range tsundoku.Tags {
+			if recentTags[tag.Name] {
+				related++
+			}
+		}
+		if related > 0 {
+			score += math.Min(float64(related)*0.5, 1)
+			recommendation.Reasons = append(recommendation.Reasons, domain.ReasonRelated)
+		}
+		if tsundoku.Status == domain.StatusReading {
+			score += 1
+			recommendation.Reasons = append(recommendation.Reasons, domain.ReasonReading)
+		}
+		score += priorityWeight(tsundoku)
+		score += rotationWeight * dailyRotation(userID, day, tsundoku.ID)
+		recommendation.Score = math.Round(score*1000) / 1000
+		recommendations[i] = recommendation
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+		return recommendations[i].ID < recommendations[j].ID
+	})
+	if len(recommendations) > count {
+		recommendations = recommendations[:count]
+	}
+	return recommendations
+}
+
+// 最近読み始めたか読み終わった積読のタグ
+func recentTagNames(history []domain.Tsundoku, now time.Time) map[string]bool {
+	since := now.Add(-recentActivityPeriod)
+	names := map[string]bool{}
+	for _, tsundoku := range history {
+		recent := tsundoku.StartedAt != nil && tsundoku.StartedAt.After(since) ||
+			tsundoku.FinishedAt != nil && tsundoku.FinishedAt.After(since)
+		if !recent {
+			continue
+		}
+		for _, tag := range tsundoku.Tags {
+			names[tag.Name] = true
+		}
+	}
+	return names
+}
+
+// ユーザー、日付、積読から決まる0以上1未満の値。乱数の代わりに使うので、同じ日なら何度呼んでも同じ
+func dailyRotation(userID int, day string, tsundokuID int) float64 {
+	sum := sha256.Sum256([]byte(strconv.Itoa(userID) + "/" + day + "/" + strconv.Itoa(tsundokuID)))
+	return float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
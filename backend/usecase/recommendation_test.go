@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+var recommendNow = time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)
+
+// 今日積んだ、締め切りも読む時間も分からない未読の積読。日替わりの点数を除くと1点
+func recommendCandidate(id int) domain.Tsundoku {
+	return domain.Tsundoku{ID: id, UserID: alice, Category: domain.CategorySite, Status: domain.StatusUnread, Priority: domain.PriorityNormal, CreatedAt: recommendNow}
+}
+
+func recommendationIDs(recommendations []domain.Recommendation) []int {
+	ids := []int{}
+	for _, recommendation := range recommendations {
+		ids = append(ids, recommendation.ID)
+	}
+	return ids
+}
+
+func TestRecommendTsundokusReasons(t *testing.T) {
+	startedAt := recommendNow.AddDate(0, 0, -3)
+	longAgo := recommendNow.AddDate(0, 0, -15)
+	tagged := func(tsundoku domain.Tsundoku, names ...string) domain.Tsundoku {
+		for _, name := range names {
+			tsundoku.Tags = append(tsundoku.Tags, domain.Tag{Name: name})
+		}
+		return tsundoku
+	}
+	recentlyStarted := tagged(recommendCandidate(100), "Go")
+	recentlyStarted.StartedAt = &startedAt
+	finishedLongAgo := tagged(recommendCandidate(101), "Go")
+	finishedLongAgo.FinishedAt = &longAgo
+
+	tests := []struct {
+		name     string
+		tsundoku func(domain.Tsundoku) domain.Tsundoku
+		history  []domain.Tsundoku
+		want     []string
+	}{
+		{"nothing special", func(t domain.Tsundoku) domain.Tsundoku { return t }, nil, []string{}},
+		{"deadline in a week", func(t domain.Tsundoku) domain.Tsundoku {
+			t.Deadline = recommendNow.AddDate(0, 0, 7)
+			return t
+		}, nil, []string{domain.ReasonDeadline}},
+		{"deadline in a month", func(t domain.Tsundoku) domain.Tsundoku {
+			t.Deadline = recommendNow.AddDate(0, 0, 30)
+			return t
+		}, nil, []string{}},
+		{"piled for a month", func(t domain.Tsundoku) domain.Tsundoku {
+			t.CreatedAt = recommendNow.AddDate(0, 0, -30)
+			return t
+		}, nil, []string{domain.ReasonStale}},
+		{"quick", func(t domain.Tsundoku) domain.Tsundoku {
+			t.RequiredMinutes = 15
+			return t
+		}, nil, []string{domain.ReasonQuick}},
+		{"not quick", func(t domain.Tsundoku) domain.Tsundoku {
+			t.RequiredMinutes = 16
+			return t
+		}, nil, []string{}},
+		{"related to recent reading", func(t domain.Tsundoku) domain.Tsundoku {
+			return tagged(t, "Go")
+		}, []domain.Tsundoku{recentlyStarted}, []string{domain.ReasonRelated}},
+		{"related to old reading", func(t domain.Tsundoku) domain.Tsundoku {
+			return tagged(t, "Go")
+		}, []domain.Tsundoku{finishedLongAgo}, []string{}},
+		{"reading", func(t domain.Tsundoku) domain.Tsundoku {
+			t.Status = domain.StatusReading
+			return t
+		}, nil, []string{domain.ReasonReading}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recommendations := RecommendTsundokus([]domain.Tsundoku{tt.tsundoku(recommendCandidate(1))}, tt.history, alice, 1, recommendNow)
+			if len(recommendations) != 1 {
+				t.Fatalf("got %d recommendations, want 1", len(recommendations))
+			}
+			if got := recommendations[0].Reasons; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got reasons %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecommendTsundokus(t *testing.T) {
+	plain := []domain.Tsundoku{recommendCandidate(1), recommendCandidate(2), recommendCandidate(3), recommendCandidate(4)}
+	tests := []struct {
+		name       string
+		candidates []domain.Tsundoku
+		count      int
+		wantLen    int
+	}{
+		{"empty input", nil, 3, 0},
+		{"fewer candidates than count", plain[:2], 3, 2},
+		{"count caps the result", plain, 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recommendations := RecommendTsundokus(tt.candidates, tt.candidates, alice, tt.count, recommendNow)
+			if len(recommendations) != tt.wantLen {
+				t.Fatalf("got %d recommendations, want %d", len(recommendations), tt.wantLen)
+			}
+			for i := 1; i < len(recommendations); i++ {
+				if recommendations[i-1].Score < recommendations[i].Score {
+					t.Errorf("not sorted by score: %v", recommendations)
+				}
+			}
+		})
+	}
+}
+
+// 日替わりの点数は同じ日のうちは変わらず、日が変わると入れ替わる
+func TestRecommendTsundokusRotation(t *testing.T) {
+	candidates := []domain.Tsundoku{recommendCandidate(1), recommendCandidate(2), recommendCandidate(3), recommendCandidate(4)}
+	morning := RecommendTsundokus(candidates, candidates, alice, 4, recommendNow)
+	evening := RecommendTsundokus(candidates, candidates, alice, 4, recommendNow.Add(12*time.Hour))
+	// 積んでからの日数は増えるので、点数ではなく順番を比べる
+	if !reflect.DeepEqual(recommendationIDs(morning), recommendationIDs(evening)) {
+		t.Errorf("changed within a day: %v, then %v", recommendationIDs(morning), recommendationIDs(evening))
+	}
+
+	firsts := map[int]bool{}
+	for day := 0; day < 30; day++ {
+		now := recommendNow.AddDate(0, 0, day)
+		firsts[RecommendTsundokus(candidates, candidates, alice, 1, now)[0].ID] = true
+	}
+	if len(firsts) < 2 {
+		t.Errorf("recommended the same tsundoku for 30 days: %v", firsts)
+	}
+
+	// 日替わりの点数だけでは、締め切りを過ぎた読みかけの積読より上にならない
+	urgent := recommendCandidate(5)
+	urgent.Deadline = recommendNow.AddDate(0, 0, -1)
+	urgent.Status = domain.StatusReading
+	withUrgent := append([]domain.Tsundoku{urgent}, candidates...)
+	for day := 0; day < 30; day++ {
+		now := recommendNow.AddDate(0, 0, day)
+		if first := RecommendTsundokus(withUrgent, withUrgent, alice, 1, now)[0]; first.ID != urgent.ID {
+			t.Errorf("day %d: got %d first, want %d", day, first.ID, urgent.ID)
+		}
+	}
+}
+
+func TestDailyRotation(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID int
+		day    string
+		id     int
+	}{
+		{"other day", alice, "2021-06-02", 1},
+		{"other user", bob, "2021-06-01", 1},
+		{"other tsundoku", alice, "2021-06-01", 2},
+	}
+	base := dailyRotation(alice, "2021-06-01", 1)
+	if base < 0 || base >= 1 {
+		t.Fatalf("got %v, want a value in [0, 1)", base)
+	}
+	if again := dailyRotation(alice, "2021-06-01", 1); again != base {
+		t.Errorf("got %v, then %v", base, again)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dailyRotation(tt.userID, tt.day, tt.id)
+			if got < 0 || got >= 1 {
+				t.Errorf("got %v, want a value in [0, 1)", got)
+			}
+			if got == base {
+				t.Errorf("got the same value %v", got)
+			}
+		})
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	book := recommendCandidate(3)
+	book.Category = domain.CategoryBook
+	done := recommendCandidate(4)
+	done.Status = domain.StatusDone
+	interactor := RecommendationInteractor{
+		TsundokuRepository: newMemoryTsundokuRepository(recommendCandidate(1), recommendCandidate(2), book, done),
+		UserRepository:     newMemoryUserRepository(domain.User{ID: alice, Timezone: domain.DefaultTimezone}),
+	}
+	tests := []struct {
+		name       string
+		count      int
+		categories []string
+		want       []int // 順番は日によって変わるのでIDの集合で比べる
+		wantErr    error
+	}{
+		{"default count", 0, nil, []int{1, 2, 3}, nil},
+		{"count", 1, nil, nil, nil},
+		{"category", 0, []string{domain.CategoryBook}, []int{3}, nil},
+		{"negative count", -1, nil, nil, domain.ErrValidation},
+		{"too many", MaxRecommendationCount + 1, nil, nil, domain.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recommendations, err := interactor.Recommend(alice, tt.count, tt.categories)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.want == nil {
+				if len(recommendations) != tt.count {
+					t.Errorf("got %d recommendations, want %d", len(recommendations), tt.count)
+				}
+				return
+			}
+			got := map[int]bool{}
+			for _, id := range recommendationIDs(recommendations) {
+				got[id] = true
+			}
+			want := map[int]bool{}
+			for _, id := range tt.want {
+				want[id] = true
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", recommendationIDs(recommendations), tt.want)
+			}
+		})
+	}
+}
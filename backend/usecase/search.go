@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// PostgreSQLの標準のパーサーは日本語を単語に分けられないので、
+// 英数字は単語ごと、それ以外の文字は2文字ずつ(bigram)に分けたものを検索の語彙にする
+
+// 検索結果の件数の上限
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// ハイライトしたメモの前後に残す文字数
+const snippetContext = 40
+
+// 英数字は小文字にした単語ごと、それ以外の文字は2文字ずつに分ける。重複は除く
+func SearchTokens(text string) []string {
+	var tokens []string
+	seen := map[string]bool{}
+	for _, token := range splitSearchText([]rune(normalizeSearchText(text))) {
+		if !seen[token.text] {
+			seen[token.text] = true
+			tokens = append(tokens, token.text)
+		}
+	}
+	return tokens
+}
+
+type searchToken struct {
+	text  string
+	start int // 文字(rune)単位の位置
+}
+
+func splitSearchText(text []rune) []searchToken {
+	var tokens []searchToken
+	start := 0
+	kind := 0 // 0: 区切り、1: 英数字、2: それ以外の文字
+	flush := func(end int) {
+		switch {
+		case kind == 1:
+			tokens = append(tokens, searchToken{string(text[start:end]), start})
+		case kind == 2 && end-start == 1:
+			tokens = append(tokens, searchToken{string(text[start:end]), start})
+		case kind == 2:
+			for i := start; i+1 < end; i++ {
+				tokens = append(tokens, searchToken{string(text[i : i+2]), i})
+			}
+		}
+	}
+	for i, r := range text {
+		k := searchRuneKind(r)
+		if k != kind {
+			flush(i)
+			start, kind = i, k
+		}
+	}
+	flush(len(text))
+	return tokens
+}
+
+func searchRuneKind(r rune) int {
+	switch {
+	case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+		return 1
+	case r >= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == 'ー'):
+		return 2
+	}
+	return 0
+}
+
+// 全角英数字を半角にして小文字にする。文字数は変わらない
+func normalizeSearchText(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '！' && r <= '～' {
+			r -= '！' - '!'
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
+
+// 検索語のトークンに一致した部分を<mark>で囲む。一致しなければ空文字
+// それ以外の部分はHTMLエスケープする
+func highlight(text string, tokens []string) string {
+	runes := []rune(text)
+	marked := make([]bool, len(runes))
+	found := false
+	normalized := []rune(normalizeSearchText(text))
+	for _, token := range tokens {
+		t := []rune(token)
+		for i := 0; i+len(t) <= len(normalized); i++ {
+			if string(normalized[i:i+len(t)]) == token {
+				for j := i; j < i+len(t); j++ {
+					marked[j] = true
+				}
+				found = true
+			}
+		}
+	}
+	if !found {
+		return ""
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && marked[j] == marked[i] {
+			j++
+		}
+		if marked[i] {
+			b.WriteString("<mark>" + html.EscapeString(string(runes[i:j])) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(string(runes[i:j])))
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// 長い文章は最初に一致した部分の前後だけを残してハイライトする
+func snippet(text string, tokens []string) string {
+	runes := []rune(text)
+	if len(runes) <= snippetContext*2 {
+		return highlight(text, tokens)
+	}
+	normalized := normalizeSearchText(text)
+	first := -1
+	for _, token := range tokens {
+		if i := strings.Index(normalized, token); i >= 0 {
+			i = len([]rune(normalized[:i]))
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+	start, end := first-snippetContext, first+snippetContext
+	prefix, suffix := "…", "…"
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(runes) {
+		end, suffix = len(runes), ""
+	}
+	return prefix + highlight(string(runes[start:end]), tokens) + suffix
+}
+
+// 一致した項目ごとのハイライト
+func searchHighlights(tsundoku domain.Tsundoku, tokens []string) map[string]string {
+	highlights := map[string]string{}
+	fields := map[string]string{
+		"title":  highlight(tsundoku.Title, tokens),
+		"author": highlight(tsundoku.Author, tokens),
+		"url":    highlight(tsundoku.URL, tokens),
+		"note":   snippet(tsundoku.Note, tokens),
+	}
+	for name, value := range fields {
+		if value != "" {
+			highlights[name] = value
+		}
+	}
+	return highlights
+}
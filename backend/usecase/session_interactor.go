@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// トークンの有効期間のデフォルト
+const (
+	DefaultAccessTokenTTL  = time.Hour
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+type SessionInteractor struct {
+	SessionRepository SessionRepository
+	AccessTokens      AccessTokens
+	AccessTokenTTL    time.Duration
+	RefreshTokenTTL   time.Duration
+}
+
+// ログインしたユーザーのセッションを作り、トークンを発行する
+func (interactor *SessionInteractor) Start(userID int) (domain.SessionToken, error) {
+	refreshToken, session, err := interactor.newSession(userID, time.Now())
+	if err != nil {
+		return domain.SessionToken{}, err
+	}
+	session, err = interactor.SessionRepository.Store(session)
+	if err != nil {
+		return domain.SessionToken{}, err
+	}
+	return interactor.token(session, refreshToken)
+}
+
+// リフレッシュトークンを新しいものに取り替えて、アクセストークンを発行し直す
+// 使ったリフレッシュトークンはもう使えない
+func (interactor *SessionInteractor) Refresh(refreshToken string) (domain.SessionToken, error) {
+	old, err := interactor.activeSession(refreshToken)
+	if err != nil {
+		return domain.SessionToken{}, err
+	}
+	newRefreshToken, session, err := interactor.newSession(old.UserID, time.Now())
+	if err != nil {
+		return domain.SessionToken{}, err
+	}
+	session, err = interactor.SessionRepository.Rotate(old, session)
+	if err != nil {
+		return domain.SessionToken{}, err
+	}
+	return interactor.token(session, newRefreshToken)
+}
+
+// ログアウト。すでに無いセッションでもエラーにしない
+func (interactor *SessionInteractor) End(refreshToken string) error {
+	if refreshToken == "" {
+		return domain.ValidationError("refreshToken is required")
+	}
+	return interactor.SessionRepository.DeleteByTokenHash(hashToken(refreshToken))
+}
+
+// アクセストークンを検証して利用者のIDを返す。データベースやLINEには問い合わせない
+func (interactor *SessionInteractor) Authenticate(accessToken string) (int, error) {
+	if accessToken == "" {
+		return 0, domain.UnauthorizedError("access token is required")
+	}
+	return interactor.AccessTokens.Verify(accessToken)
+}
+
+// 期限切れのセッションを削除する
+func (interactor *SessionInteractor) PurgeExpired() error {
+	return interactor.SessionRepository.PurgeExpiredBefore(time.Now())
+}
+
+// 有効なセッションを取得。無いか期限切れならdomain.ErrUnauthorized
+func (interactor *SessionInteractor) activeSession(refreshToken string) (domain.Session, error) {
+	if refreshToken == "" {
+		return domain.Session{}, domain.ValidationError("refreshToken is required")
+	}
+	session, err := interactor.SessionRepository.SelectByTokenHash(hashToken(refreshToken))
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.Session{}, domain.UnauthorizedError("invalid refresh token")
+	}
+	if err != nil {
+		return domain.Session{}, err
+	}
+	if !session.ExpiresAt.After(time.Now()) {
+		return domain.Session{}, domain.UnauthorizedError("refresh token expired")
+	}
+	return session, nil
+}
+
+func (interactor *SessionInteractor) newSession(userID int, now time.Time) (string, domain.Session, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", domain.Session{}, domain.InternalError(err)
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(b)
+	return refreshToken, domain.Session{
+		UserID:    userID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(interactor.refreshTokenTTL()),
+	}, nil
+}
+
+func (interactor *SessionInteractor) token(session domain.Session, refreshToken string) (domain.SessionToken, error) {
+	expiresAt := time.Now().Add(interactor.accessTokenTTL())
+	accessToken, err := interactor.AccessTokens.Issue(session.UserID, expiresAt)
+	if err != nil {
+		return domain.SessionToken{}, err
+	}
+	return domain.SessionToken{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  expiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func (interactor *SessionInteractor) accessTokenTTL() time.Duration {
+	if interactor.AccessTokenTTL <= 0 {
+		return DefaultAccessTokenTTL
+	}
+	return interactor.AccessTokenTTL
+}
+
+func (interactor *SessionInteractor) refreshTokenTTL() time.Duration {
+	if interactor.RefreshTokenTTL <= 0 {
+		return DefaultRefreshTokenTTL
+	}
+	return interactor.RefreshTokenTTL
+}
+
+// リフレッシュトークンは漏れても使えないようにハッシュで保存する
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
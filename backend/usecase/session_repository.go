@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type SessionRepository interface {
+	Store(session domain.Session) (domain.Session, error)
+	SelectByTokenHash(tokenHash string) (domain.Session, error)
+	Rotate(old domain.Session, session domain.Session) (domain.Session, error)
+	DeleteByTokenHash(tokenHash string) error
+	PurgeExpiredBefore(before time.Time) error
+}
+
+// アクセストークンの発行と検証。トークンには利用者のIDだけを入れる
+type AccessTokens interface {
+	Issue(userID int, expiresAt time.Time) (string, error)
+	// 無効・期限切れならdomain.ErrUnauthorized
+	Verify(token string) (int, error)
+}
@@ -1,19 +1,137 @@
 package usecase
 
-import "github.com/yot-sailing/TSUNTSUN/domain"
+import (
+	"errors"
+	"strings"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
 
 type TagInteractor struct {
-	TagRepository TagRepository
+	TagRepository         TagRepository
+	TsundokuRepository    TsundokuRepository
+	TsundokuTagRepository TsundokuTagRepository
 }
 
-func (interactor *TagInteractor) Add(tag domain.Tag) int {
-	return interactor.TagRepository.Store(tag)
+func normalizeTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", domain.ValidationError("name is required")
+	}
+	return name, nil
 }
 
-func (interactor *TagInteractor) GetInfo(tagID []int) []domain.Tag {
+// ユーザーが管理している積読にタグをつける。同じ名前のタグがあればそれを使う
+func (interactor *TagInteractor) AddToTsundoku(userID, tsundokuID int, name string) (domain.Tag, error) {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	if _, err := ownedTsundoku(interactor.TsundokuRepository, userID, tsundokuID); err != nil {
+		return domain.Tag{}, err
+	}
+
+	tag, err := interactor.TagRepository.SelectByName(userID, name)
+	if errors.Is(err, domain.ErrNotFound) {
+		tag, err = interactor.TagRepository.Store(domain.Tag{UserID: userID, Name: name})
+	}
+	if err != nil {
+		return domain.Tag{}, err
+	}
+
+	err = interactor.TsundokuTagRepository.Store(domain.TsundokuTag{
+		TsundokuID: tsundokuID,
+		TagID:      tag.ID,
+		UserID:     userID,
+	})
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	return tag, nil
+}
+
+func (interactor *TagInteractor) GetInfo(tagID []int) ([]domain.Tag, error) {
 	return interactor.TagRepository.Select(tagID)
 }
 
-func (interactor *TagInteractor) Delete(id int) {
-	interactor.TagRepository.Delete(id)
+// ユーザーのタグをpageの範囲で取得し、次のページのカーソルを返す。次のページがなければ空文字
+func (interactor *TagInteractor) GetInfoByUser(userID int, page Page) ([]domain.Tag, string, error) {
+	if err := page.normalize(SortCreated, SortName); err != nil {
+		return nil, "", err
+	}
+	fetch := page
+	fetch.Limit = page.fetchLimit()
+	tags, err := interactor.TagRepository.SelectByUser(userID, fetch)
+	if err != nil {
+		return nil, "", err
+	}
+	last := page.lastIndex(len(tags))
+	if last < 0 {
+		return tags, "", nil
+	}
+	return tags[:last+1], page.cursor(tagSortValue(tags[last], page.Sort), tags[last].ID), nil
+}
+
+// 同じ名前のタグがすでにあればConflict
+func (interactor *TagInteractor) Rename(userID, id int, name string) (domain.Tag, error) {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	tag, err := ownedTag(interactor.TagRepository, userID, id)
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	if tag.Name == name {
+		return tag, nil
+	}
+	if _, err := interactor.TagRepository.SelectByName(userID, name); err == nil {
+		return domain.Tag{}, domain.ConflictError("tag already exists: " + name)
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return domain.Tag{}, err
+	}
+
+	tag.Name = name
+	if err := interactor.TagRepository.Update(tag); err != nil {
+		return domain.Tag{}, err
+	}
+	return tag, nil
+}
+
+// sourceIDのタグをtargetIDのタグにまとめて、まとめた先のタグを返す
+func (interactor *TagInteractor) Merge(userID, sourceID, targetID int) (domain.Tag, error) {
+	if sourceID == targetID {
+		return domain.Tag{}, domain.ValidationError("cannot merge a tag into itself")
+	}
+	if _, err := ownedTag(interactor.TagRepository, userID, sourceID); err != nil {
+		return domain.Tag{}, err
+	}
+	target, err := ownedTag(interactor.TagRepository, userID, targetID)
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	if err := interactor.TagRepository.Merge(userID, sourceID, targetID); err != nil {
+		return domain.Tag{}, err
+	}
+	return target, nil
+}
+
+// ユーザーが管理している積読からタグを外す。同じタグのついた他の積読はそのまま
+func (interactor *TagInteractor) DeleteFromTsundoku(userID, tsundokuID, tagID int) error {
+	if _, err := ownedTsundoku(interactor.TsundokuRepository, userID, tsundokuID); err != nil {
+		return err
+	}
+	err := interactor.TsundokuTagRepository.DeleteByUser(userID, tsundokuID, tagID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NotFoundError("tag not found")
+	}
+	return err
+}
+
+// ユーザーのタグを、すべての積読へのタグ付けと一緒にゴミ箱に入れる
+func (interactor *TagInteractor) Delete(userID, tagID int) error {
+	if _, err := ownedTag(interactor.TagRepository, userID, tagID); err != nil {
+		return err
+	}
+	return interactor.TagRepository.DeleteByUser(userID, tagID)
 }
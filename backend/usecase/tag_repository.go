@@ -1,9 +1,22 @@
 package usecase
 
-import "github.com/yot-sailing/TSUNTSUN/domain"
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
 
 type TagRepository interface {
-	Store(tag domain.Tag) int
-	Select(tagID []int) []domain.Tag
-	Delete(id int)
+	Store(tag domain.Tag) (domain.Tag, error)
+	Select(tagID []int) ([]domain.Tag, error)
+	SelectByUser(userID int, page Page) ([]domain.Tag, error)
+	SelectByID(id int) (domain.Tag, error)
+	SelectByName(userID int, name string) (domain.Tag, error)
+	Update(tag domain.Tag) error
+	Merge(userID, sourceID, targetID int) error
+	DeleteByUser(userID, id int) error
+	SelectDeleted(userID int) ([]domain.Tag, error)
+	RestoreByUser(userID, id int) error
+	PurgeByUser(userID, id int) error
+	PurgeDeletedBefore(before time.Time) error
 }
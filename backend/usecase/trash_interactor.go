@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+type TrashInteractor struct {
+	TsundokuRepository TsundokuRepository
+	TagRepository      TagRepository
+}
+
+func (interactor *TrashInteractor) GetInfo(userID int) (domain.Trash, error) {
+	tsundokus, err := interactor.TsundokuRepository.SelectDeleted(userID)
+	if err != nil {
+		return domain.Trash{}, err
+	}
+	tags, err := interactor.TagRepository.SelectDeleted(userID)
+	if err != nil {
+		return domain.Trash{}, err
+	}
+	return domain.Trash{Tsundokus: tsundokus, Tags: tags}, nil
+}
+
+// ゴミ箱に入っているユーザーのタグか確認
+func (interactor *TrashInteractor) checkOwnedTag(userID, id int) error {
+	tags, err := interactor.TagRepository.SelectDeleted(userID)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if tag.ID == id {
+			return nil
+		}
+	}
+	return domain.NotFoundError("tag not found")
+}
+
+func (interactor *TrashInteractor) RestoreTsundoku(userID, id int) error {
+	if _, err := ownedDeletedTsundoku(interactor.TsundokuRepository, userID, id); err != nil {
+		return err
+	}
+	return interactor.TsundokuRepository.RestoreByUser(userID, id)
+}
+
+func (interactor *TrashInteractor) PurgeTsundoku(userID, id int) error {
+	if _, err := ownedDeletedTsundoku(interactor.TsundokuRepository, userID, id); err != nil {
+		return err
+	}
+	return interactor.TsundokuRepository.PurgeByUser(userID, id)
+}
+
+func (interactor *TrashInteractor) RestoreTag(userID, id int) error {
+	if err := interactor.checkOwnedTag(userID, id); err != nil {
+		return err
+	}
+	return interactor.TagRepository.RestoreByUser(userID, id)
+}
+
+func (interactor *TrashInteractor) PurgeTag(userID, id int) error {
+	if err := interactor.checkOwnedTag(userID, id); err != nil {
+		return err
+	}
+	return interactor.TagRepository.PurgeByUser(userID, id)
+}
+
+// ゴミ箱を空にする
+func (interactor *TrashInteractor) Empty(userID int) error {
+	trash, err := interactor.GetInfo(userID)
+	if err != nil {
+		return err
+	}
+	for _, tsundoku := range trash.Tsundokus {
+		if err := interactor.TsundokuRepository.PurgeByUser(userID, tsundoku.ID); err != nil {
+			return err
+		}
+	}
+	for _, tag := range trash.Tags {
+		if err := interactor.TagRepository.PurgeByUser(userID, tag.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 保持期間を過ぎたものを全ユーザー分削除する
+func (interactor *TrashInteractor) PurgeExpired(retention time.Duration) error {
+	before := time.Now().Add(-retention)
+	if err := interactor.TsundokuRepository.PurgeDeletedBefore(before); err != nil {
+		return err
+	}
+	return interactor.TagRepository.PurgeDeletedBefore(before)
+}
@@ -3,8 +3,9 @@ package usecase
 import "github.com/yot-sailing/TSUNTSUN/domain"
 
 type TsundokuTagRepository interface {
-	Store(tsundokuTag domain.TsundokuTag)
-	Select(userID int) []domain.TsundokuTag
-	SelectByMultiIDs(tsundokuID, userID int) []domain.TsundokuTag
-	Delete(id int)
+	Store(tsundokuTag domain.TsundokuTag) error
+	Select(userID int) ([]domain.TsundokuTag, error)
+	SelectByMultiIDs(tsundokuID, userID int) ([]domain.TsundokuTag, error)
+	// 積読からタグを外す(タグ付けだけをゴミ箱に入れる)。ついていなければdomain.ErrNotFound
+	DeleteByUser(userID, tsundokuID, tagID int) error
 }
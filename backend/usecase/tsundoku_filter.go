@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+// タグの絞り込み方
+const (
+	TagMatchAny = "or"  // どれかのタグがついている
+	TagMatchAll = "and" // すべてのタグがついている
+)
+
+// 積読一覧の絞り込み条件。ゼロ値の項目では絞り込まない
+type TsundokuFilter struct {
+	Statuses       []string
+	Tags           []string
+	TagMatch       string
+	Categories     []string
+	DeadlineBefore *time.Time
+	DeadlineAfter  *time.Time
+	HasURL         *bool
+	CreatedBefore  *time.Time
+	CreatedAfter   *time.Time
+}
+
+func (filter TsundokuFilter) validate() error {
+	for _, status := range filter.Statuses {
+		if !domain.IsValidStatus(status) {
+			return domain.ValidationError("invalid status: " + status)
+		}
+	}
+	switch filter.TagMatch {
+	case "", TagMatchAny, TagMatchAll:
+	default:
+		return domain.ValidationError("tag_mode must be and or or")
+	}
+	return nil
+}
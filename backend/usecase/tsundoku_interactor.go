@@ -1,19 +1,258 @@
 package usecase
 
-import "github.com/yot-sailing/TSUNTSUN/domain"
+import (
+	"strconv"
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
 
 type TsundokuInteractor struct {
 	TsundokuRepository TsundokuRepository
+	ProgressRepository ProgressRepository
+}
+
+// 積読の部分更新の内容。nilの項目は変更しない
+type TsundokuUpdate struct {
+	Category        *string
+	Title           *string
+	Author          *string
+	URL             *string
+	Note            *string
+	Deadline        *time.Time
+	RequiredMinutes *int
+	Priority        *int
+	TotalPages      *int
+	Language        *string
 }
 
-func (interactor *TsundokuInteractor) Add(tusndoku domain.Tsundoku) {
-	interactor.TsundokuRepository.Store(tusndoku)
+// 必須項目のチェック
+func validateTsundoku(tsundoku domain.Tsundoku) error {
+	if tsundoku.Title == "" {
+		return domain.ValidationError("title is required")
+	}
+	if tsundoku.Category == "" {
+		return domain.ValidationError("category is required")
+	}
+	if !domain.IsValidCategory(tsundoku.Category) {
+		return domain.ValidationError("category must be book or site")
+	}
+	if !domain.IsValidPriority(tsundoku.Priority) {
+		return domain.ValidationError("priority must be between 1 and 5")
+	}
+	if !domain.IsValidLanguage(tsundoku.Language) {
+		return domain.ValidationError("language must be ja or en")
+	}
+	if tsundoku.TotalPages < 0 {
+		return domain.ValidationError("totalPages must not be negative")
+	}
+	if tsundoku.TotalPages > 0 && tsundoku.CurrentPage > tsundoku.TotalPages {
+		return domain.ValidationError("totalPages must not be less than currentPage")
+	}
+	return nil
 }
 
-func (interactor *TsundokuInteractor) GetInfo(userID int) []domain.Tsundoku {
+func (interactor *TsundokuInteractor) Add(tusndoku domain.Tsundoku) (domain.Tsundoku, error) {
+	if tusndoku.Priority == 0 {
+		tusndoku.Priority = domain.PriorityNormal
+	}
+	if err := validateTsundoku(tusndoku); err != nil {
+		return domain.Tsundoku{}, err
+	}
+	// 追加したばかりの積読は未読
+	tusndoku.Status = domain.StatusUnread
+	return interactor.TsundokuRepository.Store(tusndoku)
+}
+
+func (interactor *TsundokuInteractor) GetInfo(userID int) ([]domain.Tsundoku, error) {
 	return interactor.TsundokuRepository.Select(userID)
 }
 
-func (interactor *TsundokuInteractor) Delete(id int) {
-	interactor.TsundokuRepository.Delete(id)
+// 条件に合う積読をタグをつけてpageの範囲で取得し、次のページのカーソルを返す。次のページがなければ空文字
+func (interactor *TsundokuInteractor) GetInfoByFilter(userID int, filter TsundokuFilter, page Page) ([]domain.Tsundoku, string, error) {
+	if err := filter.validate(); err != nil {
+		return nil, "", err
+	}
+	if err := page.normalize(SortCreated, SortDeadline, SortRequiredTime, SortTitle); err != nil {
+		return nil, "", err
+	}
+	fetch := page
+	fetch.Limit = page.fetchLimit()
+	tsundokus, err := interactor.TsundokuRepository.SelectByFilter(userID, filter, fetch)
+	if err != nil {
+		return nil, "", err
+	}
+	nextCursor := ""
+	if last := page.lastIndex(len(tsundokus)); last >= 0 {
+		tsundokus = tsundokus[:last+1]
+		nextCursor = page.cursor(tsundokuSortValue(tsundokus[last], page.Sort), tsundokus[last].ID)
+	}
+	items := make([]*domain.Tsundoku, len(tsundokus))
+	for i := range tsundokus {
+		items[i] = &tsundokus[i]
+	}
+	if err := interactor.fillTags(userID, items...); err != nil {
+		return nil, "", err
+	}
+	return tsundokus, nextCursor, nil
+}
+
+// 積読のタグをまとめて取得して埋める。積読1件ごとにクエリを発行しないようにする
+func (interactor *TsundokuInteractor) fillTags(userID int, tsundokus ...*domain.Tsundoku) error {
+	ids := make([]int, len(tsundokus))
+	for i, tsundoku := range tsundokus {
+		ids[i] = tsundoku.ID
+	}
+	tags, err := interactor.TsundokuRepository.SelectTags(userID, ids)
+	if err != nil {
+		return err
+	}
+	for _, tsundoku := range tsundokus {
+		tsundoku.Tags = tags[tsundoku.ID]
+		if tsundoku.Tags == nil {
+			tsundoku.Tags = []domain.Tag{}
+		}
+	}
+	return nil
+}
+
+// ユーザーが積読につけているタグを取得する
+func (interactor *TsundokuInteractor) GetTags(userID, tsundokuID int) ([]domain.Tag, error) {
+	tags, err := interactor.TsundokuRepository.SelectTags(userID, []int{tsundokuID})
+	if err != nil {
+		return nil, err
+	}
+	if tags[tsundokuID] == nil {
+		return []domain.Tag{}, nil
+	}
+	return tags[tsundokuID], nil
+}
+
+// ユーザーが管理している積読を更新して、更新後の積読を返す
+func (interactor *TsundokuInteractor) Update(userID, id int, update TsundokuUpdate) (domain.Tsundoku, error) {
+	tsundoku, err := ownedTsundoku(interactor.TsundokuRepository, userID, id)
+	if err != nil {
+		return tsundoku, err
+	}
+
+	if update.Category != nil {
+		tsundoku.Category = *update.Category
+	}
+	if update.Title != nil {
+		tsundoku.Title = *update.Title
+	}
+	if update.Author != nil {
+		tsundoku.Author = *update.Author
+	}
+	if update.URL != nil && *update.URL != tsundoku.URL {
+		tsundoku.URL = *update.URL
+		// 前のページから見積もった時間は使えないので見積もり直す
+		if tsundoku.RequiredTimeEstimated {
+			tsundoku.RequiredMinutes = 0
+		}
+		tsundoku.TextChars, tsundoku.TextWords = 0, 0
+		tsundoku.ResetEstimate()
+	}
+	if update.Note != nil {
+		tsundoku.Note = *update.Note
+	}
+	if update.Deadline != nil {
+		tsundoku.Deadline = *update.Deadline
+	}
+	if update.RequiredMinutes != nil {
+		// ユーザーが入力した時間を見積もりより優先する。空にしたら見積もり直す
+		tsundoku.RequiredMinutes = *update.RequiredMinutes
+		tsundoku.ResetEstimate()
+	}
+	if update.Priority != nil {
+		tsundoku.Priority = *update.Priority
+	}
+	if update.TotalPages != nil {
+		tsundoku.TotalPages = *update.TotalPages
+	}
+	if update.Language != nil {
+		tsundoku.Language = *update.Language
+	}
+	if err := validateTsundoku(tsundoku); err != nil {
+		return domain.Tsundoku{}, err
+	}
+
+	if err := interactor.TsundokuRepository.Update(tsundoku); err != nil {
+		return tsundoku, err
+	}
+	tsundoku, err = interactor.TsundokuRepository.SelectByID(id)
+	if err != nil {
+		return tsundoku, err
+	}
+	err = interactor.fillTags(userID, &tsundoku)
+	return tsundoku, err
+}
+
+// 読書状態を遷移させて、更新後の積読を返す
+func (interactor *TsundokuInteractor) ChangeStatus(userID, id int, status string) (domain.Tsundoku, error) {
+	tsundoku, err := ownedTsundoku(interactor.TsundokuRepository, userID, id)
+	if err != nil {
+		return tsundoku, err
+	}
+	if err := tsundoku.ChangeStatus(status, time.Now()); err != nil {
+		return tsundoku, err
+	}
+	if err := interactor.TsundokuRepository.Update(tsundoku); err != nil {
+		return tsundoku, err
+	}
+	err = interactor.fillTags(userID, &tsundoku)
+	return tsundoku, err
+}
+
+// 読み終わっていない積読から、freeMinutes分の空き時間に読むものを選ぶ。categoriesで絞り込める
+// ページ数の分かる本は、記録から測った速さで読めるだけのページを読む
+func (interactor *TsundokuInteractor) Plan(userID int, freeMinutes int, categories []string) (domain.Plan, error) {
+	if freeMinutes <= 0 || freeMinutes > MaxPlanMinutes {
+		return domain.Plan{}, domain.ValidationError("minutes must be between 1 and " + strconv.Itoa(MaxPlanMinutes))
+	}
+	filter := TsundokuFilter{Statuses: domain.UnfinishedStatuses, Categories: categories}
+	tsundokus, _, err := interactor.GetInfoByFilter(userID, filter, Page{})
+	if err != nil {
+		return domain.Plan{}, err
+	}
+	pace, err := interactor.ProgressRepository.SelectPace(userID)
+	if err != nil {
+		return domain.Plan{}, err
+	}
+	return PlanFreeTime(tsundokus, freeMinutes, pace.PagesPerMinute(), time.Now()), nil
+}
+
+// ユーザーが管理している積読をゴミ箱に入れる
+func (interactor *TsundokuInteractor) Delete(userID, id int) error {
+	if _, err := ownedTsundoku(interactor.TsundokuRepository, userID, id); err != nil {
+		return err
+	}
+	return interactor.TsundokuRepository.DeleteByUser(userID, id)
+}
+
+// ユーザーの積読を検索し、一致した部分をハイライトして返す。limitが0なら既定の件数
+func (interactor *TsundokuInteractor) Search(userID int, q string, limit int) ([]domain.SearchResult, error) {
+	tokens := SearchTokens(q)
+	if len(tokens) == 0 {
+		return nil, domain.ValidationError("q is required")
+	}
+	if limit < 0 || limit > maxSearchLimit {
+		return nil, domain.ValidationError("limit must be between 1 and 100")
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	results, err := interactor.TsundokuRepository.Search(userID, tokens, limit)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*domain.Tsundoku, len(results))
+	for i := range results {
+		results[i].Highlights = searchHighlights(results[i].Tsundoku, tokens)
+		items[i] = &results[i].Tsundoku
+	}
+	if err := interactor.fillTags(userID, items...); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
+
+func TestValidateTsundoku(t *testing.T) {
+	valid := domain.Tsundoku{Title: "title", Category: domain.CategoryBook, Priority: domain.PriorityNormal}
+	tests := []struct {
+		name   string
+		modify func(*domain.Tsundoku)
+		want   error
+	}{
+		{"book", func(*domain.Tsundoku) {}, nil},
+		{"site", func(tsundoku *domain.Tsundoku) { tsundoku.Category = domain.CategorySite }, nil},
+		{"no category", func(tsundoku *domain.Tsundoku) { tsundoku.Category = "" }, domain.ErrValidation},
+		{"unknown category", func(tsundoku *domain.Tsundoku) { tsundoku.Category = "video" }, domain.ErrValidation},
+		{"capitalized category", func(tsundoku *domain.Tsundoku) { tsundoku.Category = "Book" }, domain.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tsundoku := valid
+			tt.modify(&tsundoku)
+			err := validateTsundoku(tsundoku)
+			if tt.want == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,23 @@
 package usecase
 
-import "github.com/yot-sailing/TSUNTSUN/domain"
+import (
+	"time"
+
+	"github.com/yot-sailing/TSUNTSUN/domain"
+)
 
 type TsundokuRepository interface {
-	Store(tsundoku domain.Tsundoku)
-	Select(userID int) []domain.Tsundoku
-	Delete(id int)
+	Store(tsundoku domain.Tsundoku) (domain.Tsundoku, error)
+	Select(userID int) ([]domain.Tsundoku, error)
+	SelectByFilter(userID int, filter TsundokuFilter, page Page) ([]domain.Tsundoku, error)
+	SelectByID(id int) (domain.Tsundoku, error)
+	SelectTags(userID int, tsundokuIDs []int) (map[int][]domain.Tag, error)
+	Update(tsundoku domain.Tsundoku) error
+	Search(userID int, tokens []string, limit int) ([]domain.SearchResult, error)
+	DeleteByUser(userID, id int) error
+	SelectDeleted(userID int) ([]domain.Tsundoku, error)
+	SelectDeletedByID(id int) (domain.Tsundoku, error)
+	RestoreByUser(userID, id int) error
+	PurgeByUser(userID, id int) error
+	PurgeDeletedBefore(before time.Time) error
 }
@@ -1,26 +1,158 @@
 package usecase
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/yot-sailing/TSUNTSUN/domain"
 )
 
+// 設定できる読む速さ(1分間に読む文字数)の上限
+const maxReadingSpeed = 5000
+
+// プロフィールの部分更新。nilの項目は変更しない
+// NameとPictureを空にすると、次のログインからIDプロバイダーのものに戻す
+type ProfileUpdate struct {
+	Name         *string
+	Picture      *string
+	Timezone     *string
+	ReadingSpeed *int
+}
+
 type UserInteractor struct {
 	UserRepository UserRepository
+	Authorizer     Authorizer
 }
 
-func (interactor *UserInteractor) Add(u domain.User) {
-	interactor.UserRepository.Store(u)
+// 管理者がユーザーを作成する。役割を省略すると一般ユーザー
+func (interactor *UserInteractor) Add(actorID int, u domain.User) (domain.User, error) {
+	if _, err := interactor.Authorizer.RequireAdmin(actorID); err != nil {
+		return domain.User{}, err
+	}
+	if u.Name == "" {
+		return domain.User{}, domain.ValidationError("name is required")
+	}
+	if u.Role == "" {
+		u.Role = domain.RoleUser
+	}
+	if !domain.IsValidRole(u.Role) {
+		return domain.User{}, domain.ValidationError("role must be user or admin")
+	}
+	u.ID = 0
+	return interactor.UserRepository.Store(u)
 }
 
-func (interactor *UserInteractor) Prepare(userID string, userName string) domain.User {
-	user := interactor.UserRepository.Prepare(userID, userName)
-	return user
+// 管理者がユーザーを全件取得する
+func (interactor *UserInteractor) GetInfo(actorID int) ([]domain.User, error) {
+	if _, err := interactor.Authorizer.RequireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	return interactor.UserRepository.Select()
 }
 
-func (interactor *UserInteractor) GetInfo() []domain.User {
-	return interactor.UserRepository.Select()
+// 管理者がユーザーの役割を変える。管理者が一人もいなくなる変更はできない
+func (interactor *UserInteractor) ChangeRole(actorID int, id int, role string) (domain.User, error) {
+	if _, err := interactor.Authorizer.RequireAdmin(actorID); err != nil {
+		return domain.User{}, err
+	}
+	if !domain.IsValidRole(role) {
+		return domain.User{}, domain.ValidationError("role must be user or admin")
+	}
+	user, err := interactor.UserRepository.SelectByID(id)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+	if user.IsAdmin() {
+		if err := interactor.keepAdmin(); err != nil {
+			return domain.User{}, err
+		}
+	}
+	if err := interactor.UserRepository.UpdateRole(id, role); err != nil {
+		return domain.User{}, err
+	}
+	user.Role = role
+	return user, nil
+}
+
+// 管理者がユーザーをすぐに削除する
+// 本人の退会はデータの書き出しと猶予期間のあるAccountInteractor.ScheduleDeletionで行う
+func (interactor *UserInteractor) Delete(actorID int, id int) error {
+	if _, err := interactor.Authorizer.RequireAdmin(actorID); err != nil {
+		return err
+	}
+	user, err := interactor.UserRepository.SelectByID(id)
+	if err != nil {
+		return err
+	}
+	if user.IsAdmin() {
+		if err := interactor.keepAdmin(); err != nil {
+			return err
+		}
+	}
+	return interactor.UserRepository.Delete(id)
+}
+
+// 管理者を一人減らしても、まだ管理者が残るか確かめる
+func (interactor *UserInteractor) keepAdmin() error {
+	count, err := interactor.UserRepository.CountByRole(domain.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return domain.ConflictError("at least one admin must remain")
+	}
+	return nil
 }
 
-func (interactor *UserInteractor) Delete(id int) {
-	interactor.UserRepository.Delete(id)
+// ログインしているユーザーのプロフィール
+func (interactor *UserInteractor) GetMe(userID int) (domain.User, error) {
+	user, err := interactor.UserRepository.SelectByID(userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.User{}, domain.UnauthorizedError("user no longer exists")
+	}
+	return user, err
+}
+
+func (interactor *UserInteractor) UpdateMe(userID int, update ProfileUpdate) (domain.User, error) {
+	user, err := interactor.GetMe(userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if update.Name != nil {
+		if name := strings.TrimSpace(*update.Name); name != "" {
+			user.Name, user.NameOverridden = name, true
+		} else {
+			user.NameOverridden = false
+		}
+	}
+	if update.Picture != nil {
+		if picture := strings.TrimSpace(*update.Picture); picture != "" {
+			if !strings.HasPrefix(picture, "https://") && !strings.HasPrefix(picture, "http://") {
+				return domain.User{}, domain.ValidationError("picture must be an http or https URL")
+			}
+			user.Picture, user.PictureOverridden = picture, true
+		} else {
+			user.PictureOverridden = false
+		}
+	}
+	if update.Timezone != nil {
+		if _, err := time.LoadLocation(*update.Timezone); err != nil || *update.Timezone == "" {
+			return domain.User{}, domain.ValidationError("timezone must be an IANA time zone such as Asia/Tokyo")
+		}
+		user.Timezone = *update.Timezone
+	}
+	if update.ReadingSpeed != nil {
+		// 0は未設定に戻す
+		if *update.ReadingSpeed < 0 || *update.ReadingSpeed > maxReadingSpeed {
+			return domain.User{}, domain.ValidationError("readingSpeed must be between 0 and 5000")
+		}
+		user.ReadingSpeed = *update.ReadingSpeed
+	}
+
+	return interactor.UserRepository.UpdateProfile(user)
 }
@@ -5,8 +5,17 @@ import (
 )
 
 type UserRepository interface {
-	Store(domain.User)
-	Select() []domain.User
-	Prepare(userID string, userName string) domain.User
-	Delete(id int)
+	Store(domain.User) (domain.User, error)
+	Select() ([]domain.User, error)
+	SelectByID(id int) (domain.User, error)
+	// IDプロバイダーのアカウントに紐づくユーザーがいなければ、userを作成して紐づける
+	// いればプロフィールをIDプロバイダーのものに合わせる(domain.User.SyncProfile)
+	Prepare(identity domain.Identity, user domain.User) (domain.User, error)
+	// 名前、アイコン、タイムゾーン、読む速さを更新する
+	UpdateProfile(user domain.User) (domain.User, error)
+	UpdateRole(id int, role string) error
+	// 退会の予約を取り消す
+	CancelDeletion(id int) error
+	CountByRole(role string) (int, error)
+	Delete(id int) error
 }
@@ -7,8 +7,9 @@ chrome.tabs.query({active: true, lastFocusedWindow:true}, tabs => {
 
 var added = false;
 $(document).ready(function () {
-  if (localStorage.getItem("access_token") != null) {
-    $(".login_container").show();
+  // 設定画面で作った個人用アクセストークン
+  if (localStorage.getItem("apiToken") != null) {
+    $(".login_container").hide();
   }
   $("input#title").val(Data.Title);
   $("input#url").val(Data.URL);
@@ -28,55 +29,59 @@ $(document).ready(function () {
     $(".tag-area").append('<div class="tag">' + newTag + "</div>");
   });
 
-  $(".login_button").on("click", function () {
-    const login_url = "https://access.line.me/oauth2/v2.1/authorize?response_type=code&client_id=1656173858&redirect_uri=https://tsuntsun.herokuapp.com&state="+Math.random().toString(32).substring(2)+"&scope=profile%20openid&nonce="+Math.random().toString(32).substring(2)+"&bot_prompt=aggressive"
-
-    chrome.tabs.query({'active': true}, function(tabs) {
-      // chrome.tabs.update(tabs[0].id, {url: login_url } )
-      chrome.tabs.create({url : login_url});
-    });
+  $(".token_button").on("click", function () {
+    const token = $("input#api-token").val().trim();
+    if (token !== "") {
+      localStorage.setItem("apiToken", token);
+      $("input#api-token").val("");
+      $(".login_container").hide();
+    }
   });
 
   $("button.save").on("click", () => {
     // $(".add-view").hide();
-    const accessToken = localStorage.getItem("accessToken");
-    if (accessToken == null) { // not login 
-      alert("ログインしてください");
-      // const data = {"grant_type":'authorization_code', "code":"", "redirect_uil":Data.URL, "client_id": 1656173858, "client_secret":"e07ff58ce5052f7a5698319b3ec8493c"};
-      // const param  = {
-      //   method: "POST",
-      //   headers: {
-      //     "Content-Type": "application/json; charset=utf-8",
-      //   },
-      //   // リクエストボディ
-      //   body: JSON.stringify(data)
-      // };
-      // fetch('https://api.line.me/oauth2/v2.1/token', param).then(token => {
-      //   console.log(token);
-      //   localStorage.setItem("access_token", token);
-      // })
-    } else { //loginしていたらTODOここの動作の確認
-      const data = {URL:url, Title:$("input#title").val(), Tags:$("tags").val()};
-      const param  = {
-        method: "POST",
-        headers: {
-          "Content-Type": "application/json; charset=utf-8",
-          "Authorization": "Bearer "+accessToken,
-        },
-      
-        // リクエストボディ
-        body: JSON.stringify(data)
+    const apiToken = localStorage.getItem("apiToken");
+    if (apiToken == null) { // トークン未設定
+      alert("アクセストークンを設定してください");
+      $(".login_container").show();
+    } else {
+      const headers = {
+        "Content-Type": "application/json; charset=utf-8",
+        "Authorization": "Bearer " + apiToken,
       };
-      
-      fetch("https://tsuntsun-api.herokuapp.com/api/tsunokus", param)
-        .then((res)=>{
-          console.log( res.json() );
+      const data = {title: $("input#title").val(), url: $("input#url").val(), category: "site"};
+      const tags = $(".tag-area .tag").map(function () { return $(this).text(); }).get();
+
+      fetch("https://tsuntsun-api.herokuapp.com/api/tsundokus", {
+        method: "POST",
+        headers: headers,
+        body: JSON.stringify(data),
+      })
+        .then((res) => {
+          if (res.status === 401) {
+            // 取り消されたトークンは消して設定し直してもらう
+            localStorage.removeItem("apiToken");
+            $(".login_container").show();
+          }
+          if (!res.ok) {
+            throw new Error(res.status);
+          }
+          return res.json();
+        })
+        .then((tsundoku) => Promise.all(tags.map((tag) =>
+          fetch("https://tsuntsun-api.herokuapp.com/api/tsundokus/" + tsundoku.id + "/tags", {
+            method: "POST",
+            headers: headers,
+            body: JSON.stringify({name: tag}),
+          })
+        )))
+        .then(() => {
           $(".done-view").show();
           $(".add-view").hide();
-        }).catch((err)=>{
+        }).catch((err) => {
           console.log(err);
           $(".done-view").show();
-          $(".done").val("追加できなかった、、");
+          $(".done").text("追加できなかった、、");
         });
     }
     
@@ -12,12 +12,12 @@ function Recommend() {
   const [site, setSite] = useState<TsumiObject>();
 
   useEffect(() => {
-    defaultAxios.get("/tsundokus").then((res) => {
-      const recBook = res.data.find((t: TsumiObject) => t.category === "book");
-      setBook(recBook);
-      const recSite = res.data.find((t: TsumiObject) => t.category === "site");
-      setSite(recSite);
-    });
+    defaultAxios
+      .get("/recommendations", { params: { category: "book", count: 1 } })
+      .then((res) => setBook(res.data[0]));
+    defaultAxios
+      .get("/recommendations", { params: { category: "site", count: 1 } })
+      .then((res) => setSite(res.data[0]));
   }, []);
   return (
     <RecommendBox className="recommend">
@@ -43,7 +43,7 @@ function Recommend() {
               {site?.requiredTime && (
                 <>
                   　……　{site?.requiredTime}
-                  分で読める！
+                  で読める！
                 </>
               )}
             </>
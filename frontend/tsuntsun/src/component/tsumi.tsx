@@ -16,7 +16,14 @@ export type TsumiObject = {
   createdAt?: string;
   deadline?: string;
   id: number;
+  requiredMinutes: number;
+  priority: number;
+  language: string;
+  totalPages: number;
+  currentPage: number;
+  percentComplete: number;
   requiredTime: string;
+  requiredTimeEstimated: boolean;
   title: string;
   url: string;
   tags: TagObject[];
@@ -82,6 +89,7 @@ const Tsumi: React.FC<
   title,
   createdAt,
   requiredTime,
+  requiredTimeEstimated,
   deadline,
   tags,
   deleteFunc,
@@ -133,8 +141,9 @@ const Tsumi: React.FC<
                 <Icon>
                   <RiTimerLine size="1.5rem" />
                 </Icon>
+                {requiredTimeEstimated && "約"}
                 <NumBig>{requiredTime}</NumBig>
-                分で読める
+                で読める
               </>
             )}
           </Status>
@@ -25,6 +25,17 @@ export const useAuth = () => {
 
 export const idToken = () => localStorage.getItem("idToken");
 export const accessToken = () => localStorage.getItem("accessToken");
+// TSUNTSUNのAPIが発行したトークン
+export const sessionToken = () => localStorage.getItem("sessionToken");
+export const refreshToken = () => localStorage.getItem("refreshToken");
+
+export const saveSessionToken = (token: {
+  accessToken: string;
+  refreshToken: string;
+}) => {
+  localStorage.setItem("sessionToken", token.accessToken);
+  localStorage.setItem("refreshToken", token.refreshToken);
+};
 
 export const AuthProvider: React.FC = ({ children }) => {
   const isLoggedIn = async () => {
@@ -65,8 +76,8 @@ export const AuthProvider: React.FC = ({ children }) => {
       return false;
     }
     // stateなど一時保存したものの削除
+    // nonceはIDトークンと一緒にバックエンドで確認するのでログインするまで残す
     localStorage.setItem("state", "");
-    localStorage.setItem("nonce", "");
 
     // tokenの取得
     const params = new URLSearchParams();
@@ -94,6 +105,16 @@ export const AuthProvider: React.FC = ({ children }) => {
 
   const logout = async (): Promise<void> => {
     try {
+      await axios
+        .post("https://tsuntsun-api.herokuapp.com/api/logout", {
+          refreshToken: refreshToken(),
+        })
+        .catch((res) => {
+          console.log(res);
+        });
+      localStorage.setItem("sessionToken", "");
+      localStorage.setItem("refreshToken", "");
+
       const data = {
         client_id: process.env.REACT_APP_CHANNEL_ID,
         client_secret: process.env.REACT_APP_CHANNEL_SECRET,
@@ -4,7 +4,7 @@ import Header from "../component/header";
 import Recommend from "../component/recommend";
 import ResultArea from "../component/resultArea";
 import SearchArea from "../component/searchArea";
-import { useAuth } from "../contexts/AuthContext";
+import { saveSessionToken, useAuth } from "../contexts/AuthContext";
 import defaultAxios from "../utils/defaultAxios";
 
 function Main() {
@@ -23,9 +23,16 @@ function Main() {
 
     bodyFormData.append("id_token", idToken);
     bodyFormData.append("access_token", accessToken);
-    defaultAxios.post("/line_login", bodyFormData).then((res) => {
-      setName(res.data.name);
-    });
+    bodyFormData.append("nonce", localStorage.getItem("nonce") ?? "");
+    defaultAxios
+      .post("/line_login", bodyFormData, {
+        headers: { Authorization: "Bearer " + accessToken },
+      })
+      .then((res) => {
+        localStorage.setItem("nonce", "");
+        saveSessionToken(res.data);
+        setName(res.data.name);
+      });
     // eslint-disable-next-line react-hooks/exhaustive-deps
   }, []);
   return (
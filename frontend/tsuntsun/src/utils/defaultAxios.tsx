@@ -1,13 +1,22 @@
 import axios from "axios";
-import { accessToken } from "../contexts/AuthContext";
+import {
+  refreshToken,
+  saveSessionToken,
+  sessionToken,
+} from "../contexts/AuthContext";
+
+const baseURL = "https://tsuntsun-api.herokuapp.com/api/";
 
 const createAxiosInstance = () => {
   const axiosInstance = axios.create({
-    baseURL: "https://tsuntsun-api.herokuapp.com/api/",
+    baseURL: baseURL,
   });
 
   axiosInstance.interceptors.request.use((request) => {
-    request.headers["Authorization"] = "Bearer " + accessToken();
+    // ログインのときはLINEのアクセストークンを指定する
+    if (!request.headers["Authorization"]) {
+      request.headers["Authorization"] = "Bearer " + sessionToken();
+    }
     console.log("request");
     console.dir(request);
     return request;
@@ -19,9 +28,22 @@ const createAxiosInstance = () => {
       console.dir(response);
       return response;
     },
-    (error) => {
+    async (error) => {
       console.log("error");
       console.log(error);
+      // アクセストークンの期限が切れていたら発行し直して1回だけやり直す
+      const request = error.config;
+      if (error.response?.status === 401 && refreshToken() && !request.retried) {
+        request.retried = true;
+        const res = await axios
+          .post(baseURL + "token/refresh", { refreshToken: refreshToken() })
+          .catch((err) => err.response);
+        if (res?.status === 200) {
+          saveSessionToken(res.data);
+          request.headers["Authorization"] = "Bearer " + sessionToken();
+          return axiosInstance(request);
+        }
+      }
     }
   );
   return axiosInstance;